@@ -0,0 +1,378 @@
+package gorequests
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TokenSource return bearer token, it's called before every request is sent, so it can refresh expired token
+type TokenSource func(ctx context.Context) (string, error)
+
+// authenticator authenticate request in transport chain, see roundTripper
+type authenticator interface {
+	roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error)
+}
+
+// authTransport apply authenticator to requests of host, redirected requests to other hosts are sent without credentials
+type authTransport struct {
+	auth authenticator
+	host string
+	next http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.next.RoundTrip(req)
+	}
+	return t.auth.roundTrip(req, t.next)
+}
+
+// authError is returned by authenticators when credentials can not be got or applied, it's classified as KindAuth
+type authError struct {
+	err error
+}
+
+func (e *authError) Error() string {
+	return e.err.Error()
+}
+
+func (e *authError) Unwrap() error {
+	return e.err
+}
+
+func basicAuthorization(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// bearerAuth set bearer token of TokenSource
+type bearerAuth struct {
+	source TokenSource
+}
+
+func (a *bearerAuth) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	token, err := a.source(req.Context())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, &authError{err: fmt.Errorf("get bearer token: %w", err)}
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return next.RoundTrip(req)
+}
+
+// digestAuth do RFC 7616 digest access authentication, challenge of host is kept to authenticate following requests
+type digestAuth struct {
+	username string
+	password string
+
+	lock       sync.Mutex
+	challenges map[string]*digestChallenge // host -> last challenge
+}
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string // MD5, SHA-256, and -sess variants
+	qop       string // auth or auth-int, empty for RFC 2069 compatibility
+	userhash  bool
+	nc        int // nonce count, increased by each request with nonce
+}
+
+func newDigestAuth(username, password string) *digestAuth {
+	return &digestAuth{username: username, password: password, challenges: map[string]*digestChallenge{}}
+}
+
+func (a *digestAuth) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if err := bufferRequestBody(req); err != nil {
+		return nil, err
+	}
+
+	// authenticate with known challenge first, the server will challenge again if the nonce is stale
+	send := req
+	if authorization, ok, err := a.authorize(req, nil); err != nil {
+		return nil, err
+	} else if ok {
+		if send, err = cloneRequest(req); err != nil {
+			return nil, err
+		}
+		send.Header.Set("Authorization", authorization)
+	}
+	resp, err := next.RoundTrip(send)
+	if send != req {
+		closeRequestBody(req)
+	}
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := parseDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if challenge == nil {
+		return resp, nil
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	authorization, _, err := a.authorize(req, challenge)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := cloneRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	retry.Header.Set("Authorization", authorization)
+	return next.RoundTrip(retry)
+}
+
+// authorize build Authorization header with challenge, or known challenge of host if challenge is nil
+func (a *digestAuth) authorize(req *http.Request, challenge *digestChallenge) (string, bool, error) {
+	a.lock.Lock()
+	if challenge != nil {
+		a.challenges[req.URL.Host] = challenge
+	} else if challenge = a.challenges[req.URL.Host]; challenge == nil {
+		a.lock.Unlock()
+		return "", false, nil
+	}
+	challenge.nc++
+	nc := challenge.nc
+	a.lock.Unlock()
+
+	var body []byte
+	if challenge.qop == "auth-int" && req.GetBody != nil {
+		reader, err := req.GetBody()
+		if err != nil {
+			return "", false, err
+		}
+		if body, err = ioutil.ReadAll(reader); err != nil {
+			return "", false, err
+		}
+	}
+	cnonce := make([]byte, 16)
+	if _, err := rand.Read(cnonce); err != nil {
+		return "", false, err
+	}
+	return challenge.authorization(a.username, a.password, req.Method, req.URL.RequestURI(), body, hex.EncodeToString(cnonce), nc), true, nil
+}
+
+// authorization build Authorization header value of challenge
+func (c *digestChallenge) authorization(username, password, method, uri string, body []byte, cnonce string, nc int) string {
+	h := digestHash(c.algorithm)
+	ncValue := fmt.Sprintf("%08x", nc)
+
+	ha1 := h(username + ":" + c.realm + ":" + password)
+	if strings.HasSuffix(strings.ToUpper(c.algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	a2 := method + ":" + uri
+	if c.qop == "auth-int" {
+		a2 += ":" + h(string(body))
+	}
+	var response string
+	if c.qop == "" {
+		response = h(ha1 + ":" + c.nonce + ":" + h(a2))
+	} else {
+		response = h(ha1 + ":" + c.nonce + ":" + ncValue + ":" + cnonce + ":" + c.qop + ":" + h(a2))
+	}
+
+	if c.userhash {
+		username = h(username + ":" + c.realm)
+	}
+	params := []string{
+		fmt.Sprintf("username=%s", quoteDigestParam(username)),
+		fmt.Sprintf("realm=%s", quoteDigestParam(c.realm)),
+		fmt.Sprintf("uri=%s", quoteDigestParam(uri)),
+	}
+	if c.algorithm != "" {
+		params = append(params, "algorithm="+c.algorithm)
+	}
+	params = append(params, fmt.Sprintf("nonce=%s", quoteDigestParam(c.nonce)))
+	if c.qop != "" {
+		params = append(params, "nc="+ncValue, fmt.Sprintf("cnonce=%s", quoteDigestParam(cnonce)), "qop="+c.qop)
+	}
+	params = append(params, fmt.Sprintf("response=%s", quoteDigestParam(response)))
+	if c.opaque != "" {
+		params = append(params, fmt.Sprintf("opaque=%s", quoteDigestParam(c.opaque)))
+	}
+	if c.userhash {
+		params = append(params, "userhash=true")
+	}
+	return "Digest " + strings.Join(params, ", ")
+}
+
+func digestHash(algorithm string) func(string) string {
+	var newHash func() hash.Hash = md5.New
+	if strings.HasPrefix(strings.ToUpper(algorithm), "SHA-256") {
+		newHash = sha256.New
+	}
+	return func(s string) string {
+		h := newHash()
+		_, _ = h.Write([]byte(s))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// parseDigestChallenge return the strongest supported digest challenge of WWW-Authenticate headers, nil if not found
+func parseDigestChallenge(headers []string) *digestChallenge {
+	var (
+		best     *digestChallenge
+		bestRank int
+	)
+	for _, header := range headers {
+		for _, params := range splitAuthChallenges(header) {
+			scheme, params := params[0], params[1:]
+			if !strings.EqualFold(scheme, "Digest") {
+				continue
+			}
+			c := &digestChallenge{}
+			var qops []string
+			for i := 0; i+1 < len(params); i += 2 {
+				switch v := params[i+1]; strings.ToLower(params[i]) {
+				case "realm":
+					c.realm = v
+				case "nonce":
+					c.nonce = v
+				case "opaque":
+					c.opaque = v
+				case "algorithm":
+					c.algorithm = v
+				case "qop":
+					qops = strings.Split(v, ",")
+				case "userhash":
+					c.userhash = strings.EqualFold(v, "true")
+				}
+			}
+			// auth is preferred, auth-int is used only if it's the only choice
+			for _, qop := range qops {
+				qop = strings.TrimSpace(qop)
+				if qop == "auth" || (qop == "auth-int" && c.qop == "") {
+					c.qop = qop
+				}
+			}
+			rank := map[string]int{"": 1, "MD5": 1, "MD5-SESS": 1, "SHA-256": 2, "SHA-256-SESS": 2}[strings.ToUpper(c.algorithm)]
+			if c.nonce == "" || rank == 0 || (len(qops) > 0 && c.qop == "") {
+				continue // unsupported algorithm or qop
+			}
+			if rank > bestRank {
+				best, bestRank = c, rank
+			}
+		}
+	}
+	return best
+}
+
+// splitAuthChallenges split WWW-Authenticate header into challenges, each challenge is scheme followed by param name and value pairs
+func splitAuthChallenges(header string) [][]string {
+	var (
+		res [][]string
+		cur []string
+		s   = header
+	)
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			break
+		}
+		token := s
+		if idx := strings.IndexAny(s, " \t,="); idx >= 0 {
+			token = s[:idx]
+		}
+		s = strings.TrimLeft(s[len(token):], " \t")
+		if !strings.HasPrefix(s, "=") {
+			// token without value starts a new challenge
+			if cur != nil {
+				res = append(res, cur)
+			}
+			cur = []string{token}
+			continue
+		}
+		s = strings.TrimLeft(s[1:], " \t")
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			value, s = readQuotedString(s)
+		} else {
+			value = s
+			if idx := strings.IndexAny(s, " \t,"); idx >= 0 {
+				value = s[:idx]
+			}
+			s = s[len(value):]
+		}
+		if cur != nil {
+			cur = append(cur, token, value)
+		}
+	}
+	if cur != nil {
+		res = append(res, cur)
+	}
+	return res
+}
+
+// readQuotedString read quoted string at the start of s, return unquoted value and the rest
+func readQuotedString(s string) (string, string) {
+	buf := new(strings.Builder)
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				buf.WriteByte(s[i])
+			}
+		case '"':
+			return buf.String(), s[i+1:]
+		default:
+			buf.WriteByte(s[i])
+		}
+	}
+	return buf.String(), ""
+}
+
+func quoteDigestParam(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// bufferRequestBody read body into memory if it can not be replayed, so request can be sent again
+func bufferRequestBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	bs, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return fmt.Errorf("read request body: %w", err)
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(string(bs))), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(bs))
+	return nil
+}
+
+// cloneRequest clone request with a new body
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	res := req.Clone(req.Context())
+	if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		res.Body = body
+	}
+	return res, nil
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
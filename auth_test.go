@@ -0,0 +1,182 @@
+package gorequests_test
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+var digestParamRegexp = regexp.MustCompile(`(\w+)=(?:"((?:[^"\\]|\\.)*)"|([^,\s]*))`)
+
+// testDigestResponse compute response of digest authorization, independent of the implementation
+func testDigestResponse(params map[string]string, password, method string, body []byte) string {
+	newHash := md5.New
+	if strings.HasPrefix(params["algorithm"], "SHA-256") {
+		newHash = func() hash.Hash { return sha256.New() }
+	}
+	h := func(s string) string {
+		v := newHash()
+		v.Write([]byte(s))
+		return hex.EncodeToString(v.Sum(nil))
+	}
+	ha1 := h(params["username"] + ":" + params["realm"] + ":" + password)
+	if strings.HasSuffix(params["algorithm"], "-sess") {
+		ha1 = h(ha1 + ":" + params["nonce"] + ":" + params["cnonce"])
+	}
+	a2 := method + ":" + params["uri"]
+	if params["qop"] == "auth-int" {
+		a2 += ":" + h(string(body))
+	}
+	if params["qop"] == "" {
+		return h(ha1 + ":" + params["nonce"] + ":" + h(a2))
+	}
+	return h(ha1 + ":" + params["nonce"] + ":" + params["nc"] + ":" + params["cnonce"] + ":" + params["qop"] + ":" + h(a2))
+}
+
+func parseTestDigest(header string) map[string]string {
+	res := map[string]string{}
+	for _, m := range digestParamRegexp.FindAllStringSubmatch(strings.TrimPrefix(header, "Digest "), -1) {
+		res[m[1]] = m[2] + m[3]
+	}
+	return res
+}
+
+// newDigestServer require digest auth of user:pass, and echo request body
+func newDigestServer(challenge string, unauthorized *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		params := parseTestDigest(r.Header.Get("Authorization"))
+		if params["response"] == "" || params["response"] != testDigestResponse(params, "pass", r.Method, body) ||
+			params["uri"] != r.URL.RequestURI() || params["username"] != "user" || params["opaque"] != "op" {
+			atomic.AddInt32(unauthorized, 1)
+			w.Header().Add("WWW-Authenticate", `Basic realm="basic"`)
+			w.Header().Add("WWW-Authenticate", challenge)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Nc", params["nc"])
+		_, _ = w.Write(body)
+	}))
+}
+
+func Test_Auth(t *testing.T) {
+	as := assert.New(t)
+	logger := gorequests.WithLogger(gorequests.NewDiscardLogger())
+
+	t.Run("basic and bearer", func(t *testing.T) {
+		fac := gorequests.NewFactory(logger, gorequests.WithBasicAuth("user", "pass"))
+		as.Equal("Basic dXNlcjpwYXNz", fac.New(http.MethodGet, joinHttpBinURL("/headers")).MustJSONPathString("headers.Authorization"))
+
+		req := gorequests.New(http.MethodGet, joinHttpBinURL("/headers")).WithLogger(gorequests.NewDiscardLogger()).WithBearerToken("token")
+		as.Equal("Bearer token", req.MustJSONPathString("headers.Authorization"))
+	})
+
+	t.Run("credentials are redacted in log", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer secret-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		defer server.Close()
+		recorder := new(testRecordLogger)
+		req := gorequests.New(http.MethodGet, server.URL).WithLogger(recorder).WithBearerToken("secret-token")
+		_, err := req.WithExpectStatus().Text()
+		as.Nil(err)
+		as.Equal("REDACTED", req.LogMessage().RequestHeader.Get("Authorization"))
+		as.Equal("Bearer secret-token", req.RequestHeader().Get("Authorization"))
+		as.NotEmpty(recorder.infos)
+		for _, v := range recorder.infos {
+			as.NotContains(v, "secret-token")
+		}
+	})
+
+	t.Run("token source", func(t *testing.T) {
+		var count int32
+		fac := gorequests.NewFactory(logger, gorequests.WithBearerTokenSource(func(ctx context.Context) (string, error) {
+			if atomic.AddInt32(&count, 1) > 2 {
+				return "", errors.New("expired")
+			}
+			return "token", nil
+		}))
+		as.Equal("Bearer token", fac.New(http.MethodGet, joinHttpBinURL("/headers")).MustJSONPathString("headers.Authorization"))
+		as.Equal("Bearer token", fac.New(http.MethodGet, joinHttpBinURL("/headers")).MustJSONPathString("headers.Authorization"))
+		_, err := fac.New(http.MethodGet, joinHttpBinURL("/headers")).Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "get bearer token: expired")
+
+		// credentials are not sent to redirected host
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+		}))
+		defer other.Close()
+		redirect := httptest.NewServer(http.RedirectHandler(other.URL, http.StatusFound))
+		defer redirect.Close()
+		text, err := gorequests.New(http.MethodGet, redirect.URL).WithLogger(gorequests.NewDiscardLogger()).
+			WithBearerTokenSource(func(ctx context.Context) (string, error) { return "token", nil }).Text()
+		as.Nil(err)
+		as.Equal("", text)
+	})
+
+	t.Run("digest", func(t *testing.T) {
+		for _, challenge := range []string{
+			`Digest realm="test@example.org", qop="auth, auth-int", algorithm=SHA-256, nonce="n1", opaque="op"`,
+			`Digest realm="test@example.org", qop="auth-int", algorithm=MD5, nonce="n1", opaque="op"`,
+			`Digest realm="test@example.org", qop="auth", algorithm=SHA-256-sess, nonce="n1", opaque="op"`,
+			`Digest realm="test@example.org", nonce="n1", opaque="op"`,
+			`Digest realm="a, \"b\"", qop=auth, algorithm=MD5, nonce="n1", opaque="op", Digest realm="test@example.org", qop="auth", algorithm=SHA-256, nonce="n1", opaque="op"`,
+		} {
+			var unauthorized int32
+			server := newDigestServer(challenge, &unauthorized)
+			text, err := gorequests.New(http.MethodPost, server.URL+"/dir/index.html?a=1").WithLogger(gorequests.NewDiscardLogger()).
+				WithDigestAuth("user", "pass").WithBody(strings.NewReader("body")).Text()
+			as.Nil(err, challenge)
+			as.Equal("body", text, challenge)
+			as.Equal(int32(1), unauthorized, challenge)
+			server.Close()
+		}
+	})
+
+	t.Run("digest shared challenge", func(t *testing.T) {
+		var unauthorized int32
+		server := newDigestServer(`Digest realm="test@example.org", qop="auth", algorithm=SHA-256, nonce="n1", opaque="op"`, &unauthorized)
+		defer server.Close()
+
+		fac := gorequests.NewFactory(logger, gorequests.WithDigestAuth("user", "pass"))
+		for i, nc := range []string{"00000001", "00000002", "00000003"} {
+			val, err := fac.New(http.MethodGet, server.URL).ResponseHeaderByKey("X-Nc")
+			as.Nil(err)
+			as.Equal(nc, val, i)
+		}
+		as.Equal(int32(1), unauthorized)
+
+		// wrong password get 401 after one retry
+		status, err := gorequests.New(http.MethodGet, server.URL).WithLogger(gorequests.NewDiscardLogger()).WithDigestAuth("user", "wrong").ResponseStatus()
+		as.Nil(err)
+		as.Equal(http.StatusUnauthorized, status)
+		as.Equal(int32(3), unauthorized)
+	})
+
+	t.Run("digest rfc 7616 example", func(t *testing.T) {
+		params := map[string]string{
+			"username": "Mufasa", "realm": "http-auth@example.org", "uri": "/dir/index.html", "qop": "auth", "nc": "00000001",
+			"nonce":  "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+			"cnonce": "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ",
+		}
+		as.Equal("8ca523f5e9506fed4657c9700eebdbec", testDigestResponse(params, "Circle of Life", http.MethodGet, nil))
+		params["algorithm"] = "SHA-256"
+		as.Equal("753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1", testDigestResponse(params, "Circle of Life", http.MethodGet, nil))
+	})
+}
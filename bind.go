@@ -0,0 +1,314 @@
+package gorequests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidBinding is returned by Bind when client struct or its func fields are invalid
+var ErrInvalidBinding = errors.New("gorequests: invalid binding")
+
+var (
+	contextType       = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	requestOptionType = reflect.TypeOf(RequestOption(nil))
+	bytesType         = reflect.TypeOf([]byte(nil))
+)
+
+// Bind fill func fields of client, which is a pointer to struct, with implementations which send request by requester
+//
+//	type UserAPI struct {
+//		GetUser    func(ctx context.Context, req GetUserReq) (*User, error) `method:"GET" path:"/users/{id}"`
+//		DeleteUser func(ctx context.Context, req DeleteUserReq) error       `method:"DELETE" path:"/users/{id}" expect:"204"`
+//	}
+//
+//	api := new(UserAPI)
+//	err := gorequests.Bind(api, gorequests.NewFactory(gorequests.WithBaseURL("https://api/")))
+//
+// arguments of func are optional context.Context, optional request struct encoded by WithRequestStruct,
+// and optional variadic RequestOption, in this order,
+// results of func are error, or (T, error) with response body decoded into T, []byte and string are the raw body,
+// response status is checked by WithExpectStatus with codes of expect tag, or status check of requester if there is no tag,
+// any 2xx status is expected by default, WithExpectStatus option of caller override both,
+// fields without method tag are left untouched.
+func Bind(client interface{}, requester Requester) error {
+	v := reflect.ValueOf(client)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: client should be pointer to struct, but got %T", ErrInvalidBinding, client)
+	}
+	v = v.Elem()
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if _, ok := field.Tag.Lookup("method"); !ok {
+			continue
+		}
+		if field.Type.Kind() != reflect.Func || field.PkgPath != "" {
+			return fmt.Errorf("%w: field %s should be exported func", ErrInvalidBinding, field.Name)
+		}
+		b, err := newBinding(field, requester)
+		if err != nil {
+			return fmt.Errorf("%w: field %s: %s", ErrInvalidBinding, field.Name, err)
+		}
+		v.Field(i).Set(reflect.MakeFunc(field.Type, b.call))
+	}
+	return nil
+}
+
+// binding is the implementation of one func field
+type binding struct {
+	requester  Requester
+	method     string
+	path       string
+	expect     []int
+	ctxIndex   int          // index of context argument, -1 if not exist
+	reqIndex   int          // index of request struct argument, -1 if not exist
+	optsIndex  int          // index of variadic RequestOption argument, -1 if not exist
+	resultType reflect.Type // nil if func only return error
+}
+
+func newBinding(field reflect.StructField, requester Requester) (*binding, error) {
+	b := &binding{
+		requester: requester,
+		method:    strings.ToUpper(field.Tag.Get("method")),
+		path:      field.Tag.Get("path"),
+		ctxIndex:  -1,
+		reqIndex:  -1,
+		optsIndex: -1,
+	}
+	if b.method == "" {
+		return nil, fmt.Errorf("empty method tag")
+	}
+	if expect := field.Tag.Get("expect"); expect != "" {
+		for _, s := range strings.Split(expect, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return nil, fmt.Errorf("invalid expect tag %q", expect)
+			}
+			b.expect = append(b.expect, code)
+		}
+	}
+
+	typ := field.Type
+	numIn := typ.NumIn()
+	if typ.IsVariadic() {
+		if typ.In(numIn-1) != reflect.SliceOf(requestOptionType) {
+			return nil, fmt.Errorf("variadic argument should be ...RequestOption, but got %s", typ.In(numIn-1))
+		}
+		numIn--
+		b.optsIndex = numIn
+	}
+	i := 0
+	if i < numIn && typ.In(i) == contextType {
+		b.ctxIndex = i
+		i++
+	}
+	if i < numIn {
+		in := typ.In(i)
+		if in.Kind() == reflect.Ptr {
+			in = in.Elem()
+		}
+		if in.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("request argument should be struct or pointer to struct, but got %s", typ.In(i))
+		}
+		b.reqIndex = i
+		i++
+	}
+	if i < numIn {
+		return nil, fmt.Errorf("unsupported argument %s", typ.In(i))
+	}
+
+	switch {
+	case typ.NumOut() == 1 && typ.Out(0) == errorType:
+	case typ.NumOut() == 2 && typ.Out(1) == errorType:
+		b.resultType = typ.Out(0)
+	default:
+		return nil, fmt.Errorf("func should return error or (T, error)")
+	}
+	return b, nil
+}
+
+func (b *binding) call(args []reflect.Value) []reflect.Value {
+	req := b.requester.New(b.method, b.path)
+	// expect tag override status check of requester, and is overridden by options of caller
+	if len(b.expect) > 0 {
+		req.WithExpectStatus(b.expect...)
+	} else if !req.isCheckStatus {
+		req.WithExpectStatus()
+	}
+	if b.ctxIndex >= 0 {
+		if ctx, ok := args[b.ctxIndex].Interface().(context.Context); ok {
+			req.WithContext(ctx)
+		}
+	}
+	if b.reqIndex >= 0 {
+		req.WithRequestStruct(args[b.reqIndex].Interface())
+	}
+	if b.optsIndex >= 0 {
+		for _, opt := range args[b.optsIndex].Interface().([]RequestOption) {
+			if err := opt(req); err != nil {
+				req.SetError(err)
+				break
+			}
+		}
+	}
+
+	if b.resultType == nil {
+		return []reflect.Value{errorValue(req.Unmarshal(nil))}
+	}
+
+	var (
+		res = reflect.New(b.resultType)
+		err error
+	)
+	switch {
+	case b.resultType == bytesType:
+		var bs []byte
+		if bs, err = req.Bytes(); err == nil {
+			res.Elem().SetBytes(bs)
+		}
+	case b.resultType.Kind() == reflect.String:
+		var s string
+		if s, err = req.Text(); err == nil {
+			res.Elem().SetString(s)
+		}
+	case b.resultType.Kind() == reflect.Ptr:
+		val := reflect.New(b.resultType.Elem())
+		if err = req.Unmarshal(val.Interface()); err == nil {
+			res.Elem().Set(val)
+		}
+	default:
+		err = req.Unmarshal(res.Interface())
+	}
+	if err != nil {
+		return []reflect.Value{reflect.Zero(b.resultType), errorValue(err)}
+	}
+	return []reflect.Value{res.Elem(), errorValue(nil)}
+}
+
+func errorValue(err error) reflect.Value {
+	if err == nil {
+		return reflect.Zero(errorType)
+	}
+	return reflect.ValueOf(&err).Elem()
+}
+
+// requestStruct is the encoded request struct, see WithRequestStruct
+type requestStruct struct {
+	query    map[string][]string
+	header   map[string][]string
+	path     map[string]string
+	bodyKind string // json, form or raw, empty if no body
+	body     interface{}
+}
+
+func encodeRequestStruct(v interface{}) (*requestStruct, error) {
+	res := &requestStruct{header: map[string][]string{}, path: map[string]string{}}
+	var err error
+	if res.query, err = queryToMap(v); err != nil {
+		return nil, err
+	}
+	vv, ok := indirectValue(reflect.ValueOf(v))
+	if !ok {
+		return res, nil
+	}
+
+	if err := encodeTagValues(res.header, vv, "header"); err != nil {
+		return nil, err
+	}
+	path := map[string][]string{}
+	if err := encodeTagValues(path, vv, "path"); err != nil {
+		return nil, err
+	}
+	for k, v := range path {
+		res.path[k] = strings.Join(v, ",")
+	}
+
+	fields, err := getTagFields(vv.Type(), "body")
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) > 1 {
+		return nil, fmt.Errorf("multi body fields %s and %s", fields[0].field, fields[1].field)
+	}
+	for _, f := range fields {
+		fv, ok := fieldByIndex(vv, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		switch f.name {
+		case "json", "raw":
+			res.body = fv.Interface()
+		case "form":
+			if res.body, err = queryToMap(fv.Interface()); err != nil {
+				return nil, fmt.Errorf("body field %s: %w", f.field, err)
+			}
+		default:
+			return nil, fmt.Errorf("body field %s: unknown body kind %q, should be json, form or raw", f.field, f.name)
+		}
+		res.bodyKind = f.name
+	}
+	return res, nil
+}
+
+// encodeTagValues encode fields of struct v with tag into vals, values are formatted like query
+func encodeTagValues(vals map[string][]string, v reflect.Value, tagName string) error {
+	fields, err := getTagFields(v.Type(), tagName)
+	if err != nil {
+		return err
+	}
+	for i := range fields {
+		f := &fields[i]
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			continue
+		}
+		if err := encodeQueryValue(vals, f.name, f.field, f, fv); err != nil {
+			return fmt.Errorf("%s: %w", tagName, err)
+		}
+	}
+	return nil
+}
+
+// apply set encoded request struct to r with With* methods
+func (s *requestStruct) apply(r *Request) {
+	for _, k := range sortedKeys(s.query) {
+		for _, v := range s.query[k] {
+			r.WithQuery(k, v)
+		}
+	}
+	for _, k := range sortedKeys(s.header) {
+		for _, v := range s.header[k] {
+			r.WithHeader(k, v)
+		}
+	}
+	if len(s.path) > 0 {
+		r.WithPathParams(s.path)
+	}
+	switch s.bodyKind {
+	case "json":
+		r.WithJSON(s.body)
+	case "form":
+		r.WithBody(url.Values(s.body.(map[string][]string)).Encode())
+		r.configParamFactor(func(r *Request) {
+			r.header.Set("Content-Type", "application/x-www-form-urlencoded")
+		})
+	case "raw":
+		r.WithBody(s.body)
+	}
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,164 @@
+package gorequests_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+type bindEcho struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Args    map[string]string `json:"args"`
+	Headers map[string]string `json:"headers"`
+	Form    map[string]string `json:"form"`
+	JSON    interface{}       `json:"json"`
+}
+
+type bindUserReq struct {
+	ID      int64     `path:"id"`
+	Fields  []string  `query:"fields,omitempty,comma"`
+	Since   time.Time `query:"since,omitempty,unix"`
+	Token   string    `header:"X-Token,omitempty"`
+	Body    *testUser `body:"json,omitempty"`
+	Ignored string
+}
+
+type bindFormReq struct {
+	Name string `path:"name"`
+	Form struct {
+		A string   `query:"a"`
+		B []string `query:"b"`
+	} `body:"form"`
+}
+
+type bindAPI struct {
+	GetUser    func(ctx context.Context, req bindUserReq) (*bindEcho, error)                                   `method:"GET" path:"anything/users/{id}"`
+	UpdateUser func(ctx context.Context, req *bindUserReq, opts ...gorequests.RequestOption) (bindEcho, error) `method:"put" path:"anything/users/{id}"`
+	PostForm   func(req bindFormReq) (map[string]interface{}, error)                                           `method:"POST" path:"anything/{name}"`
+	Status     func(ctx context.Context) error                                                                 `method:"GET" path:"status/418"`
+	Created    func(opts ...gorequests.RequestOption) error                                                    `method:"GET" path:"status/201" expect:"200"`
+	Raw        func() (string, error)                                                                          `method:"GET" path:"status/204"`
+	Untouched  func()
+}
+
+func Test_Bind(t *testing.T) {
+	as := assert.New(t)
+	api := new(bindAPI)
+	as.Nil(gorequests.Bind(api, gorequests.NewFactory(
+		gorequests.WithBaseURL(httpBinServer.URL),
+		gorequests.WithLogger(gorequests.NewDiscardLogger()),
+	)))
+	ctx := context.Background()
+
+	t.Run("path, query and header", func(t *testing.T) {
+		res, err := api.GetUser(ctx, bindUserReq{ID: 1, Fields: []string{"a", "b"}, Since: time.Unix(100, 0), Token: "t", Ignored: "x"})
+		as.Nil(err)
+		as.Equal(http.MethodGet, res.Method)
+		as.Equal(httpBinServer.URL+"/anything/users/1?fields=a%2Cb&since=100", res.URL)
+		as.Equal("t", res.Headers["X-Token"])
+		as.Nil(res.JSON)
+	})
+
+	t.Run("json body and options", func(t *testing.T) {
+		res, err := api.UpdateUser(ctx, &bindUserReq{ID: 2, Body: &testUser{ID: 2, Name: "bob"}}, gorequests.WithHeader("X-Opt", "1"))
+		as.Nil(err)
+		as.Equal(http.MethodPut, res.Method)
+		as.Equal(map[string]interface{}{"id": float64(2), "name": "bob"}, res.JSON)
+		as.Equal("1", res.Headers["X-Opt"])
+		as.Equal("application/json", res.Headers["Content-Type"])
+		as.Empty(res.Headers["X-Token"])
+	})
+
+	t.Run("form body", func(t *testing.T) {
+		req := bindFormReq{Name: "a b"}
+		req.Form.A, req.Form.B = "1", []string{"2", "3"}
+		res, err := api.PostForm(req)
+		as.Nil(err)
+		as.Equal(httpBinServer.URL+"/anything/a%20b", res["url"])
+		as.Equal(map[string]interface{}{"a": "1", "b": []interface{}{"2", "3"}}, res["form"])
+	})
+
+	t.Run("status", func(t *testing.T) {
+		err := api.Status(ctx)
+		var httpErr *gorequests.HTTPError
+		as.True(errors.As(err, &httpErr))
+		as.Equal(418, httpErr.StatusCode)
+
+		as.True(errors.Is(api.Created(), gorequests.ErrUnexpectedStatus))
+		as.Nil(api.Created(gorequests.WithExpectStatus(http.StatusCreated)))
+
+		// status check of requester is kept if there is no expect tag, and overridden by expect tag
+		teapot := new(bindAPI)
+		as.Nil(gorequests.Bind(teapot, gorequests.NewFactory(
+			gorequests.WithBaseURL(httpBinServer.URL),
+			gorequests.WithLogger(gorequests.NewDiscardLogger()),
+			gorequests.WithExpectStatus(http.StatusTeapot),
+		)))
+		as.Nil(teapot.Status(ctx))
+		as.True(errors.Is(teapot.Created(), gorequests.ErrUnexpectedStatus))
+
+		s, err := api.Raw()
+		as.Nil(err)
+		as.Equal("", s)
+		as.Nil(api.Untouched)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := api.GetUser(canceled, bindUserReq{ID: 1})
+		as.True(errors.Is(err, gorequests.ErrCanceled))
+
+		_, err = api.UpdateUser(ctx, nil)
+		as.True(errors.Is(err, gorequests.ErrUnresolvedPathParam))
+	})
+
+	t.Run("invalid binding", func(t *testing.T) {
+		requester := gorequests.NewFactory()
+		for _, client := range []interface{}{
+			bindAPI{},
+			&struct {
+				F func(int) error `method:"GET"`
+			}{},
+			&struct {
+				F func(context.Context) `method:"GET"`
+			}{},
+			&struct {
+				F func(...string) error `method:"GET"`
+			}{},
+			&struct {
+				F func() error `method:""`
+			}{},
+			&struct {
+				F func() error `method:"GET" expect:"ok"`
+			}{},
+			&struct {
+				f func() error `method:"GET"`
+			}{},
+		} {
+			as.True(errors.Is(gorequests.Bind(client, requester), gorequests.ErrInvalidBinding), "%T", client)
+		}
+	})
+}
+
+func Test_WithRequestStruct(t *testing.T) {
+	as := assert.New(t)
+
+	_, err := gorequests.New(http.MethodPost, "http://127.0.0.1/{id}").WithRequestStruct(struct {
+		A string `body:"json"`
+		B string `body:"raw"`
+	}{}).Response()
+	as.True(errors.Is(err, gorequests.ErrInvalidRequest))
+	as.Contains(err.Error(), "multi body fields A and B")
+
+	_, err = gorequests.New(http.MethodPost, "http://127.0.0.1/{id}").WithRequestStruct(struct {
+		A string `body:"xml"`
+	}{}).Response()
+	as.Contains(err.Error(), `unknown body kind "xml"`)
+}
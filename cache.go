@@ -0,0 +1,450 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStatus is the cache status of response
+type CacheStatus string
+
+const (
+	CacheStatusMiss        CacheStatus = "MISS"        // response is fetched from upstream
+	CacheStatusHit         CacheStatus = "HIT"         // fresh response is served from cache
+	CacheStatusRevalidated CacheStatus = "REVALIDATED" // stale response is validated by upstream with 304
+	CacheStatusStale       CacheStatus = "STALE"       // stale response is served, and revalidated in background
+)
+
+// cacheRevalidateTimeout timeout of background revalidation of stale-while-revalidate
+const cacheRevalidateTimeout = time.Minute
+
+// CacheEntry is a stored response
+type CacheEntry struct {
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	RequestTime  time.Time   `json:"request_time"`
+	ResponseTime time.Time   `json:"response_time"`
+	VaryHeader   http.Header `json:"vary_header"` // request header selected by Vary of response
+}
+
+// CacheStorage store cache entry, Get return nil entry if key not exist
+type CacheStorage interface {
+	Get(key string) (*CacheEntry, error)
+	Set(key string, entry *CacheEntry) error
+	Delete(key string) error
+}
+
+// Cache is a private http cache which follow RFC 9111, it support:
+//
+//	Cache-Control of request: no-store, no-cache, max-age, min-fresh, max-stale
+//	Cache-Control of response: no-store, no-cache, max-age, must-revalidate, stale-while-revalidate
+//	Expires, Age, Date, heuristic freshness with Last-Modified
+//	conditional request with ETag/If-None-Match and Last-Modified/If-Modified-Since
+//	Vary
+//	invalidation by unsafe method
+//
+// only GET and HEAD response is stored, responses of different Authorization, Proxy-Authorization and Cookie
+// are stored separately, cache can be shared by Factory and Session with WithCache.
+type Cache struct {
+	storage      CacheStorage
+	now          func() time.Time
+	revalidating sync.Map // key -> struct{}, background revalidation in flight
+}
+
+// NewCache create http cache with storage, see NewMemoryCacheStorage and NewDiskCacheStorage
+func NewCache(storage CacheStorage) *Cache {
+	return &Cache{storage: storage, now: time.Now}
+}
+
+type cacheStatusKey struct{}
+
+func withCacheStatus(ctx context.Context, status *CacheStatus) context.Context {
+	return context.WithValue(ctx, cacheStatusKey{}, status)
+}
+
+func setCacheStatus(ctx context.Context, status CacheStatus) {
+	if v, ok := ctx.Value(cacheStatusKey{}).(*CacheStatus); ok {
+		*v = status
+	}
+}
+
+type cacheTransport struct {
+	cache *Cache
+	next  http.RoundTripper
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := cacheKey(req.Method, req.URL.String(), req.Header)
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && resp.StatusCode < 400 && isUnsafeMethod(req.Method) {
+			t.invalidate(req.URL.String(), req.Header)
+		}
+		return resp, err
+	}
+
+	reqCC := parseCacheControl(req.Header.Values("Cache-Control"))
+	if reqCC.has("no-store") || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	entry, err := t.cache.storage.Get(key)
+	if err != nil || (entry != nil && !entry.matchVary(req)) {
+		entry = nil
+	}
+	if entry == nil {
+		setCacheStatus(req.Context(), CacheStatusMiss)
+		return t.fetch(req, key)
+	}
+
+	now := t.cache.now()
+	respCC := parseCacheControl(entry.Header.Values("Cache-Control"))
+	age := entry.currentAge(now)
+	lifetime := entry.freshnessLifetime(respCC)
+	if !reqCC.has("no-cache") && !respCC.has("no-cache") {
+		if isFreshFor(reqCC, respCC, age, lifetime) {
+			setCacheStatus(req.Context(), CacheStatusHit)
+			return entry.response(req, age), nil
+		}
+		if swr, ok := respCC.seconds("stale-while-revalidate"); ok && !respCC.has("must-revalidate") && age < lifetime+swr {
+			setCacheStatus(req.Context(), CacheStatusStale)
+			t.revalidateInBackground(req, key, entry)
+			return entry.response(req, age), nil
+		}
+	}
+
+	etag, lastModified := entry.Header.Get("ETag"), entry.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		setCacheStatus(req.Context(), CacheStatusMiss)
+		return t.fetch(req, key)
+	}
+
+	resp, reqTime, respTime, err := t.revalidate(req, entry)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusNotModified {
+		setCacheStatus(req.Context(), CacheStatusMiss)
+		return t.store(req, key, resp, reqTime, respTime)
+	}
+	_ = resp.Body.Close()
+	entry = entry.refresh(resp, reqTime, respTime)
+	_ = t.cache.storage.Set(key, entry)
+	setCacheStatus(req.Context(), CacheStatusRevalidated)
+	return entry.response(req, entry.currentAge(t.cache.now())), nil
+}
+
+// fetch send request, and store response if it is cacheable
+func (t *cacheTransport) fetch(req *http.Request, key string) (*http.Response, error) {
+	reqTime := t.cache.now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.store(req, key, resp, reqTime, t.cache.now())
+}
+
+// revalidate send conditional request with validators of entry
+func (t *cacheTransport) revalidate(req *http.Request, entry *CacheEntry) (*http.Response, time.Time, time.Time, error) {
+	condReq := req.Clone(req.Context())
+	if etag := entry.Header.Get("ETag"); etag != "" {
+		condReq.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+		condReq.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	reqTime := t.cache.now()
+	resp, err := t.next.RoundTrip(condReq)
+	return resp, reqTime, t.cache.now(), err
+}
+
+func (t *cacheTransport) revalidateInBackground(req *http.Request, key string, entry *CacheEntry) {
+	if _, loaded := t.cache.revalidating.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cacheRevalidateTimeout)
+	bgReq := req.Clone(ctx)
+	go func() {
+		defer cancel()
+		defer t.cache.revalidating.Delete(key)
+
+		var resp *http.Response
+		var reqTime, respTime time.Time
+		var err error
+		if entry.Header.Get("ETag") != "" || entry.Header.Get("Last-Modified") != "" {
+			resp, reqTime, respTime, err = t.revalidate(bgReq, entry)
+		} else {
+			reqTime = t.cache.now()
+			resp, err = t.next.RoundTrip(bgReq)
+			respTime = t.cache.now()
+		}
+		if err != nil {
+			return
+		}
+		if resp.StatusCode == http.StatusNotModified {
+			_ = resp.Body.Close()
+			_ = t.cache.storage.Set(key, entry.refresh(resp, reqTime, respTime))
+			return
+		}
+		if resp, err = t.store(bgReq, key, resp, reqTime, respTime); err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+}
+
+// store read response body and store it if response is cacheable, return response with re-readable body
+func (t *cacheTransport) store(req *http.Request, key string, resp *http.Response, reqTime, respTime time.Time) (*http.Response, error) {
+	respCC := parseCacheControl(resp.Header.Values("Cache-Control"))
+	if !isCacheableResponse(resp, respCC) {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	entry := &CacheEntry{
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		RequestTime:  reqTime,
+		ResponseTime: respTime,
+	}
+	for _, name := range varyHeaders(resp.Header) {
+		if entry.VaryHeader == nil {
+			entry.VaryHeader = http.Header{}
+		}
+		entry.VaryHeader[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+	}
+	_ = t.cache.storage.Set(key, entry)
+	return resp, nil
+}
+
+// invalidate delete entries of url stored with the same credentials as header
+func (t *cacheTransport) invalidate(url string, header http.Header) {
+	_ = t.cache.storage.Delete(cacheKey(http.MethodGet, url, header))
+	_ = t.cache.storage.Delete(cacheKey(http.MethodHead, url, header))
+}
+
+// response build http response of entry
+func (e *CacheEntry) response(req *http.Request, age time.Duration) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// refresh return new entry with header updated by 304 response
+func (e *CacheEntry) refresh(resp *http.Response, reqTime, respTime time.Time) *CacheEntry {
+	entry := *e
+	entry.Header = e.Header.Clone()
+	for k, v := range resp.Header {
+		switch k {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding":
+			continue
+		}
+		entry.Header[k] = v
+	}
+	entry.RequestTime, entry.ResponseTime = reqTime, respTime
+	return &entry
+}
+
+func (e *CacheEntry) matchVary(req *http.Request) bool {
+	for _, name := range varyHeaders(e.Header) {
+		if name == "*" {
+			return false
+		}
+		if strings.Join(e.VaryHeader.Values(name), ", ") != strings.Join(req.Header.Values(name), ", ") {
+			return false
+		}
+	}
+	return true
+}
+
+// currentAge RFC 9111 4.2.3
+func (e *CacheEntry) currentAge(now time.Time) time.Duration {
+	dateValue := e.ResponseTime
+	if date, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		dateValue = date
+	}
+	var ageValue time.Duration
+	if age, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil && age > 0 {
+		ageValue = time.Duration(age) * time.Second
+	}
+
+	apparentAge := e.ResponseTime.Sub(dateValue)
+	if apparentAge < 0 {
+		apparentAge = 0
+	}
+	correctedAgeValue := ageValue + e.ResponseTime.Sub(e.RequestTime)
+	initialAge := apparentAge
+	if correctedAgeValue > initialAge {
+		initialAge = correctedAgeValue
+	}
+	return initialAge + now.Sub(e.ResponseTime)
+}
+
+// freshnessLifetime RFC 9111 4.2.1 and 4.2.2
+func (e *CacheEntry) freshnessLifetime(cc cacheControl) time.Duration {
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		return maxAge
+	}
+
+	date, dateErr := http.ParseTime(e.Header.Get("Date"))
+	if dateErr != nil {
+		date = e.ResponseTime
+	}
+	if expiresHeader := e.Header.Get("Expires"); expiresHeader != "" {
+		expires, err := http.ParseTime(expiresHeader)
+		if err != nil {
+			return 0 // invalid Expires means already expired
+		}
+		return expires.Sub(date)
+	}
+	if lastModified, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil && heuristicCacheableStatus[e.StatusCode] {
+		if d := date.Sub(lastModified); d > 0 {
+			return d / 10
+		}
+	}
+	return 0
+}
+
+func isFreshFor(reqCC, respCC cacheControl, age, lifetime time.Duration) bool {
+	if maxAge, ok := reqCC.seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+	if minFresh, ok := reqCC.seconds("min-fresh"); ok {
+		return lifetime-age >= minFresh
+	}
+	if age < lifetime {
+		return true
+	}
+	if reqCC.has("max-stale") && !respCC.has("must-revalidate") {
+		maxStale, ok := reqCC.seconds("max-stale")
+		return !ok || age-lifetime <= maxStale
+	}
+	return false
+}
+
+// heuristicCacheableStatus RFC 9110 15.1
+var heuristicCacheableStatus = map[int]bool{
+	200: true, 203: true, 204: true, 206: true, 300: true, 301: true, 308: true,
+	404: true, 405: true, 410: true, 414: true, 501: true,
+}
+
+func isCacheableResponse(resp *http.Response, cc cacheControl) bool {
+	if resp.StatusCode == http.StatusPartialContent || !heuristicCacheableStatus[resp.StatusCode] {
+		return false
+	}
+	if cc.has("no-store") {
+		return false
+	}
+	for _, name := range varyHeaders(resp.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+	_, hasMaxAge := cc.seconds("max-age")
+	return hasMaxAge || cc.has("no-cache") ||
+		resp.Header.Get("Expires") != "" ||
+		resp.Header.Get("ETag") != "" ||
+		resp.Header.Get("Last-Modified") != ""
+}
+
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
+
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// cacheKey return key of request, credentials are part of the key so responses are not shared by different users,
+// they are hashed to keep secrets out of storage
+func cacheKey(method, url string, header http.Header) string {
+	key := method + " " + url
+	h, hasCredentials := sha256.New(), false
+	for _, name := range coalesceCredentialHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			hasCredentials = true
+			fmt.Fprintf(h, "%s: %s\n", name, strings.Join(values, ", "))
+		}
+	}
+	if hasCredentials {
+		key += " " + hex.EncodeToString(h.Sum(nil))
+	}
+	return key
+}
+
+// cacheControl is parsed Cache-Control header, directive name is lower case
+type cacheControl map[string]string
+
+func parseCacheControl(values []string) cacheControl {
+	cc := cacheControl{}
+	for _, v := range values {
+		for _, directive := range strings.Split(v, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+			name, value := directive, ""
+			if idx := strings.IndexByte(directive, '='); idx >= 0 {
+				name, value = directive[:idx], strings.Trim(strings.TrimSpace(directive[idx+1:]), `"`)
+			}
+			cc[strings.ToLower(strings.TrimSpace(name))] = value
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	v, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return time.Duration(i) * time.Second, true
+}
@@ -0,0 +1,138 @@
+package gorequests
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// NewMemoryCacheStorage create in-memory cache storage, least recently used entry is evicted when
+// there are more than maxEntries entries, no limit if maxEntries <= 0
+func NewMemoryCacheStorage(maxEntries int) CacheStorage {
+	return &memoryCacheStorage{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+// NewDiskCacheStorage create on-disk cache storage, every entry is a json file in dir
+func NewDiskCacheStorage(dir string) CacheStorage {
+	return &diskCacheStorage{dir: dir}
+}
+
+// memory cache storage
+type memoryCacheStorage struct {
+	lock       sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+func (s *memoryCacheStorage) Get(key string) (*CacheEntry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+	s.ll.MoveToFront(e)
+	return e.Value.(*memoryCacheItem).entry, nil
+}
+
+func (s *memoryCacheStorage) Set(key string, entry *CacheEntry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.items[key]; ok {
+		s.ll.MoveToFront(e)
+		e.Value.(*memoryCacheItem).entry = entry
+		return nil
+	}
+	s.items[key] = s.ll.PushFront(&memoryCacheItem{key: key, entry: entry})
+	if s.maxEntries > 0 && s.ll.Len() > s.maxEntries {
+		oldest := s.ll.Back()
+		s.ll.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+func (s *memoryCacheStorage) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e, ok := s.items[key]; ok {
+		s.ll.Remove(e)
+		delete(s.items, key)
+	}
+	return nil
+}
+
+// disk cache storage
+type diskCacheStorage struct {
+	dir string
+}
+
+func (s *diskCacheStorage) Get(key string) (*CacheEntry, error) {
+	bs, err := ioutil.ReadFile(s.filename(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entry := new(CacheEntry)
+	if err := json.Unmarshal(bs, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *diskCacheStorage) Set(key string, entry *CacheEntry) error {
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	// write to temp file and rename, so reader never see partial file
+	tmp, err := ioutil.TempFile(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(bs); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.filename(key))
+}
+
+func (s *diskCacheStorage) Delete(key string) error {
+	if err := os.Remove(s.filename(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *diskCacheStorage) filename(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
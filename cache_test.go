@@ -0,0 +1,161 @@
+package gorequests_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCacheServer(hits *int32) *httptest.Server {
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(hits, 1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/last-modified":
+			// already stale by Age header
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Age", "100")
+			w.Header().Set("Last-Modified", lastModified)
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/swr":
+			w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=600")
+			w.Header().Set("Age", "100")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		_, _ = w.Write([]byte(strconv.Itoa(int(n))))
+	}))
+}
+
+func Test_Cache(t *testing.T) {
+	as := assert.New(t)
+
+	get := func(fac *gorequests.Factory, url string, headers ...string) (string, gorequests.CacheStatus) {
+		r := fac.New(http.MethodGet, url).WithLogProducer(gorequests.NewPrinterLogProducer())
+		for i := 0; i+1 < len(headers); i += 2 {
+			r.WithHeader(headers[i], headers[i+1])
+		}
+		text, err := r.Text()
+		as.Nil(err)
+		as.Equal(string(r.CacheStatus()), r.LogMessage().CacheStatus)
+		return text, r.CacheStatus()
+	}
+
+	for name, storage := range map[string]func() gorequests.CacheStorage{
+		"memory": func() gorequests.CacheStorage { return gorequests.NewMemoryCacheStorage(100) },
+		"disk":   func() gorequests.CacheStorage { return gorequests.NewDiskCacheStorage(t.TempDir()) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			var hits int32
+			server := newCacheServer(&hits)
+			defer server.Close()
+			fac := gorequests.NewFactory(gorequests.WithCache(gorequests.NewCache(storage())))
+
+			t.Run("fresh", func(t *testing.T) {
+				text, status := get(fac, server.URL+"/fresh")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				text2, status := get(fac, server.URL+"/fresh")
+				as.Equal(gorequests.CacheStatusHit, status)
+				as.Equal(text, text2)
+
+				_, status = get(fac, server.URL+"/fresh", "Cache-Control", "no-cache")
+				as.Equal(gorequests.CacheStatusMiss, status)
+			})
+
+			t.Run("etag", func(t *testing.T) {
+				text, status := get(fac, server.URL+"/etag")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				text2, status := get(fac, server.URL+"/etag")
+				as.Equal(gorequests.CacheStatusRevalidated, status)
+				as.Equal(text, text2)
+			})
+
+			t.Run("last-modified", func(t *testing.T) {
+				text, status := get(fac, server.URL+"/last-modified")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				text2, status := get(fac, server.URL+"/last-modified")
+				as.Equal(gorequests.CacheStatusRevalidated, status)
+				as.Equal(text, text2)
+			})
+
+			t.Run("vary", func(t *testing.T) {
+				en, status := get(fac, server.URL+"/vary", "Accept-Language", "en")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				zh, status := get(fac, server.URL+"/vary", "Accept-Language", "zh")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				as.NotEqual(en, zh)
+				zh2, status := get(fac, server.URL+"/vary", "Accept-Language", "zh")
+				as.Equal(gorequests.CacheStatusHit, status)
+				as.Equal(zh, zh2)
+			})
+
+			t.Run("credentials", func(t *testing.T) {
+				alice, status := get(fac, server.URL+"/fresh?credentials", "Authorization", "Bearer alice")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				bob, status := get(fac, server.URL+"/fresh?credentials", "Authorization", "Bearer bob")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				as.NotEqual(alice, bob)
+				alice2, status := get(fac, server.URL+"/fresh?credentials", "Authorization", "Bearer alice")
+				as.Equal(gorequests.CacheStatusHit, status)
+				as.Equal(alice, alice2)
+				_, status = get(fac, server.URL+"/fresh?credentials", "Cookie", "session=alice")
+				as.Equal(gorequests.CacheStatusMiss, status)
+
+				// credentials applied by transport
+				r := fac.New(http.MethodGet, server.URL+"/fresh?credentials").WithLogger(gorequests.NewDiscardLogger()).
+					WithBearerTokenSource(func(ctx context.Context) (string, error) { return "carol", nil })
+				_, err := r.Text()
+				as.Nil(err)
+				as.Equal(gorequests.CacheStatusMiss, r.CacheStatus())
+			})
+
+			t.Run("no-store", func(t *testing.T) {
+				text, _ := get(fac, server.URL+"/no-store")
+				text2, status := get(fac, server.URL+"/no-store")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				as.NotEqual(text, text2)
+			})
+
+			t.Run("invalidate", func(t *testing.T) {
+				text, _ := get(fac, server.URL+"/fresh?invalidate")
+				_, err := fac.New(http.MethodPost, server.URL+"/fresh?invalidate").Text()
+				as.Nil(err)
+				text2, status := get(fac, server.URL+"/fresh?invalidate")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				as.NotEqual(text, text2)
+			})
+
+			t.Run("stale-while-revalidate", func(t *testing.T) {
+				text, status := get(fac, server.URL+"/swr")
+				as.Equal(gorequests.CacheStatusMiss, status)
+				before := atomic.LoadInt32(&hits)
+				text2, status := get(fac, server.URL+"/swr")
+				as.Equal(gorequests.CacheStatusStale, status)
+				as.Equal(text, text2)
+				as.Eventually(func() bool { return atomic.LoadInt32(&hits) > before }, time.Second, 10*time.Millisecond)
+			})
+		})
+	}
+}
@@ -0,0 +1,821 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	pathParamRegexp = regexp.MustCompile(`\{([^{}]+)\}`)
+	invalidIdentChr = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// commonInitialisms is upper cased in go names, e.g. petId -> PetID
+var commonInitialisms = map[string]bool{
+	"API": true, "CPU": true, "CSS": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "SQL": true, "SSH": true, "TLS": true, "TTL": true,
+	"UI": true, "UID": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// reservedArgs are names used in generated method body
+var reservedArgs = map[string]bool{"c": true, "ctx": true, "body": true, "params": true, "req": true, "res": true, "err": true}
+
+type generator struct {
+	doc         *document
+	err         error
+	imports     map[string]bool
+	decls       map[string]string  // type name to declaration
+	components  map[string]*schema // go name of component schema to schema
+	structs     map[string]bool    // struct type names
+	stringTypes map[string]bool    // type names of which underlying type is string
+	errorTypes  map[string]bool    // types decoded from error response
+	inline      map[*schema]string // declared inline schemas, shared by allOf
+	methods     map[string]bool
+}
+
+// generate generate go client package pkg from OpenAPI 3 document, source is shown in header comment
+func generate(spec []byte, pkg, source string) ([]byte, error) {
+	doc, err := parseDocument(spec)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		doc:         doc,
+		imports:     map[string]bool{"context": true, "net/http": true, "strings": true},
+		decls:       map[string]string{},
+		components:  map[string]*schema{},
+		structs:     map[string]bool{},
+		stringTypes: map[string]bool{},
+		errorTypes:  map[string]bool{},
+		inline:      map[*schema]string{},
+		methods:     map[string]bool{},
+	}
+
+	// reserve names of components, so inline types will not take them
+	names := sortedKeys(doc.Components.Schemas)
+	for _, name := range names {
+		typeName := goName(name)
+		if _, ok := g.components[typeName]; ok {
+			return nil, fmt.Errorf("schema %q conflicts with another schema of go name %s", name, typeName)
+		}
+		g.components[typeName] = doc.Components.Schemas[name]
+		g.decls[typeName] = ""
+		switch g.schemaKind(doc.Components.Schemas[name]) {
+		case "struct":
+			g.structs[typeName] = true
+		case "string":
+			g.stringTypes[typeName] = true
+		}
+	}
+	for _, name := range names {
+		g.declareComponent(goName(name), doc.Components.Schemas[name])
+	}
+
+	methods := []string{}
+	for _, path := range sortedKeys(doc.Paths) {
+		item := doc.Paths[path]
+		for _, v := range item.operations() {
+			code, err := g.operation(path, item, v)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", v.method, path, err)
+			}
+			methods = append(methods, code)
+		}
+	}
+	if g.err != nil {
+		return nil, g.err
+	}
+	return g.render(pkg, source, methods)
+}
+
+func (g *generator) fail(err error) {
+	if g.err == nil {
+		g.err = err
+	}
+}
+
+func (g *generator) render(pkg, source string, methods []string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Code generated by gorequests-gen from %s. DO NOT EDIT.\n\n", source)
+	title := strings.TrimSpace(g.doc.Info.Title + " " + g.doc.Info.Version)
+	if title != "" {
+		fmt.Fprintf(buf, "// Package %s is the client of %s.\n", pkg, title)
+	}
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+
+	buf.WriteString("import (\n")
+	for _, v := range sortedKeys(g.imports) {
+		fmt.Fprintf(buf, "\t%q\n", v)
+	}
+	buf.WriteString("\n\t\"github.com/jloha/gorequests\"\n)\n\n")
+
+	baseURL := ""
+	if len(g.doc.Servers) > 0 {
+		baseURL = g.doc.Servers[0].URL
+	}
+	fmt.Fprintf(buf, `// DefaultBaseURL is the url of the first server in spec
+const DefaultBaseURL = %s
+
+// Client send requests of operations in spec
+type Client struct {
+	baseURL   string
+	requester gorequests.Requester
+}
+
+// NewClient create client, DefaultBaseURL is used if baseURL is empty,
+// requests are created by requester, which is gorequests.NewFactory() if nil
+func NewClient(baseURL string, requester gorequests.Requester) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if requester == nil {
+		requester = gorequests.NewFactory()
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), requester: requester}
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string) *gorequests.Request {
+	return c.requester.New(method, c.baseURL+path).WithContext(ctx)
+}
+`, strconv.Quote(baseURL))
+
+	for _, v := range methods {
+		buf.WriteString("\n" + v)
+	}
+	for _, name := range sortedKeys(g.decls) {
+		buf.WriteString("\n" + g.decls[name])
+	}
+	for _, name := range sortedKeys(g.errorTypes) {
+		fmt.Fprintf(buf, `
+// As%[1]s return %[1]s decoded from error response body, if err is *gorequests.HTTPError
+func As%[1]s(err error) (*%[1]s, bool) {
+	var httpErr *gorequests.HTTPError
+	if !errors.As(err, &httpErr) {
+		return nil, false
+	}
+	res, ok := httpErr.Result.(*%[1]s)
+	return res, ok
+}
+`, name)
+	}
+
+	bs, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, buf.Bytes())
+	}
+	return bs, nil
+}
+
+// types
+
+// schemaKind return kind of schema without declaring types: struct, slice, map, string, scalar or any
+func (g *generator) schemaKind(s *schema) string {
+	s, err := g.doc.resolveSchema(s)
+	if err != nil || s == nil {
+		return "any"
+	}
+	switch {
+	case len(s.OneOf) > 0 || len(s.AnyOf) > 0:
+		return "any"
+	case isStruct(s):
+		return "struct"
+	case len(s.AllOf) == 1:
+		return g.schemaKind(s.AllOf[0])
+	}
+	switch s.Type.Name {
+	case "string":
+		if s.Format == "byte" {
+			return "slice"
+		}
+		if s.Format == "date-time" {
+			return "struct"
+		}
+		return "string"
+	case "integer", "number", "boolean":
+		return "scalar"
+	case "array":
+		return "slice"
+	}
+	if s.additional() != nil || s.Type.Name == "object" {
+		return "map"
+	}
+	return "any"
+}
+
+func isStruct(s *schema) bool {
+	if s.Ref != "" || len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		return false
+	}
+	return len(s.Properties) > 0 || len(s.AllOf) > 1 || (len(s.AllOf) == 1 && s.Type.Name == "object")
+}
+
+// nillable report whether zero value of typ is nil, optional fields of other types are pointers
+func (g *generator) nillable(typ string) bool {
+	for _, prefix := range []string{"[]", "map[", "*", "interface{}", "json.RawMessage"} {
+		if strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	if s, ok := g.components[typ]; ok {
+		kind := g.schemaKind(s)
+		return kind == "slice" || kind == "map" || kind == "any"
+	}
+	return false
+}
+
+func (g *generator) uniqueName(name string) string {
+	res := name
+	for i := 2; ; i++ {
+		if _, ok := g.decls[res]; !ok {
+			return res
+		}
+		res = name + strconv.Itoa(i)
+	}
+}
+
+// goType return go type of schema, inline struct and enum are declared with name hint
+func (g *generator) goType(s *schema, hint string) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		name, err := refName(s.Ref, "schemas")
+		if err == nil {
+			if _, ok := g.doc.Components.Schemas[name]; !ok {
+				err = fmt.Errorf("unresolved ref %q", s.Ref)
+			}
+		}
+		if err != nil {
+			g.fail(err)
+			return "interface{}"
+		}
+		return goName(name)
+	}
+	if name, ok := g.inline[s]; ok {
+		return name
+	}
+	switch {
+	case len(s.OneOf) > 0 || len(s.AnyOf) > 0:
+		g.imports["encoding/json"] = true
+		return "json.RawMessage"
+	case isStruct(s):
+		g.inline[s] = g.uniqueName(hint)
+		return g.declareStruct(g.inline[s], s)
+	case len(s.AllOf) == 1:
+		return g.goType(s.AllOf[0], hint)
+	}
+
+	switch s.Type.Name {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.imports["time"] = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		if len(s.Enum) > 0 {
+			g.inline[s] = g.uniqueName(hint)
+			return g.declareEnum(g.inline[s], s)
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(s.Items, hint+"Item")
+	}
+	if v := s.additional(); v != nil {
+		return "map[string]" + g.goType(v, hint+"Value")
+	}
+	if s.Type.Name == "object" {
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+func (g *generator) declareComponent(name string, s *schema) {
+	switch {
+	case isStruct(s):
+		g.declareStruct(name, s)
+	case s.Ref == "" && s.Type.Name == "string" && s.Format == "" && len(s.Enum) > 0:
+		g.declareEnum(name, s)
+	default:
+		typ := g.goType(s, name)
+		g.decls[name] = comment(name, s.Description) + fmt.Sprintf("type %s %s\n", name, typ)
+	}
+}
+
+func (g *generator) declareStruct(name string, s *schema) string {
+	g.decls[name] = "" // reserve for recursive schema
+	g.structs[name] = true
+
+	props, required := map[string]*schema{}, map[string]bool{}
+	g.collectProperties(s, props, required, 0)
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(comment(name, s.Description))
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	fields := map[string]bool{}
+	for _, prop := range sortedKeys(props) {
+		p := props[prop]
+		field := uniqueField(goName(prop), fields)
+		typ := g.goType(p, name+field)
+		nullable := p.Nullable || p.Type.Nullable
+		if (!required[prop] || nullable) && !g.nillable(typ) {
+			typ = "*" + typ
+		}
+		tag := prop
+		if !required[prop] {
+			tag += ",omitempty"
+		}
+		buf.WriteString(comment("", p.Description))
+		fmt.Fprintf(buf, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	buf.WriteString("}\n")
+	g.decls[name] = buf.String()
+	return name
+}
+
+// collectProperties collect properties and required of schema, properties of allOf are merged
+func (g *generator) collectProperties(s *schema, props map[string]*schema, required map[string]bool, depth int) {
+	s, err := g.doc.resolveSchema(s)
+	if err != nil {
+		g.fail(err)
+		return
+	}
+	if s == nil || depth > 32 {
+		return
+	}
+	for _, v := range s.AllOf {
+		g.collectProperties(v, props, required, depth+1)
+	}
+	for k, v := range s.Properties {
+		props[k] = v
+	}
+	for _, k := range s.Required {
+		required[k] = true
+	}
+}
+
+func (g *generator) declareEnum(name string, s *schema) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString(comment(name, s.Description))
+	fmt.Fprintf(buf, "type %s string\n", name)
+	g.stringTypes[name] = true
+	consts := map[string]bool{}
+	lines := []string{}
+	for _, v := range s.Enum {
+		value, ok := v.(string)
+		if !ok || goName(value) == "" {
+			continue
+		}
+		constName := uniqueField(name+goName(value), consts)
+		lines = append(lines, fmt.Sprintf("\t%s %s = %q\n", constName, name, value))
+	}
+	if len(lines) > 0 {
+		buf.WriteString("\nconst (\n" + strings.Join(lines, "") + ")\n")
+	}
+	g.decls[name] = buf.String()
+	return name
+}
+
+// operations
+
+func (g *generator) operation(path string, item *pathItem, mo methodOperation) (string, error) {
+	op := mo.op
+	name := goName(op.OperationID)
+	if name == "" {
+		name = goName(strings.ToLower(mo.method) + " " + pathParamRegexp.ReplaceAllString(path, "by $1"))
+	}
+	if g.methods[name] {
+		return "", fmt.Errorf("duplicate operation name %s", name)
+	}
+	g.methods[name] = true
+
+	// params of operation override params of path item
+	var (
+		params []*parameter
+		index  = map[string]int{}
+	)
+	for _, v := range append(append([]*parameter{}, item.Parameters...), op.Parameters...) {
+		p, err := g.doc.resolveParameter(v)
+		if err != nil {
+			return "", err
+		}
+		key := p.In + ":" + p.Name
+		if i, ok := index[key]; ok {
+			params[i] = p
+			continue
+		}
+		index[key] = len(params)
+		params = append(params, p)
+	}
+
+	var (
+		args        = []string{"ctx context.Context"}
+		argNames    = map[string]bool{}
+		chain       []string
+		fieldParams []*parameter
+	)
+	for k := range reservedArgs {
+		argNames[k] = true
+	}
+	// path params in order of path
+	tmpl := path
+	for _, match := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		i, ok := index["path:"+match[1]]
+		if !ok {
+			return "", fmt.Errorf("path param %s is not defined", match[1])
+		}
+		p := params[i]
+		arg := uniqueField(lowerName(p.Name), argNames)
+		typ := g.goType(p.Schema, name+goName(p.Name))
+		args = append(args, arg+" "+typ)
+		key := invalidIdentChr.ReplaceAllString(p.Name, "_")
+		tmpl = strings.Replace(tmpl, match[0], "{"+key+"}", 1)
+		chain = append(chain, fmt.Sprintf("WithPathParam(%q, %s)", key, g.toString(arg, typ)))
+	}
+	for _, p := range params {
+		if p.In == "query" || p.In == "header" || p.In == "cookie" {
+			fieldParams = append(fieldParams, p)
+		}
+	}
+
+	body, err := g.doc.resolveRequestBody(op.RequestBody)
+	if err != nil {
+		return "", err
+	}
+	if body != nil && len(body.Content) > 0 {
+		contentType, media := pickContent(body.Content)
+		if isJSON(contentType) {
+			typ := g.goType(media.Schema, name+"Request")
+			if !g.nillable(typ) {
+				typ = "*" + typ
+			}
+			args = append(args, "body "+typ)
+			chain = append(chain, "WithJSON(body)")
+		} else {
+			args = append(args, "body interface{}")
+			chain = append(chain, fmt.Sprintf("WithBody(body).WithHeader(\"Content-Type\", %q)", contentType))
+		}
+	}
+
+	var fields []paramField
+	if len(fieldParams) > 0 {
+		var paramsType string
+		paramsType, fields = g.declareParams(g.uniqueName(name+"Params"), name, fieldParams)
+		args = append(args, "params *"+paramsType)
+	}
+
+	// responses
+	var (
+		expect     []string
+		anyStatus  bool
+		resultType string
+		errorTypes []string
+	)
+	for _, code := range sortedKeys(op.Responses) {
+		resp, err := g.doc.resolveResponse(op.Responses[code])
+		if err != nil {
+			return "", err
+		}
+		contentType, media := pickContent(resp.Content)
+		hasSchema := media != nil && media.Schema != nil && isJSON(contentType)
+		if strings.HasPrefix(code, "2") {
+			if _, err := strconv.Atoi(code); err == nil {
+				expect = append(expect, code)
+			} else {
+				anyStatus = true
+			}
+			if resultType == "" && hasSchema {
+				resultType = g.goType(media.Schema, name+"Response")
+			}
+			continue
+		}
+		if hasSchema {
+			typ := g.goType(media.Schema, name+"Error")
+			if !containsString(errorTypes, typ) {
+				errorTypes = append(errorTypes, typ)
+			}
+		}
+	}
+	if anyStatus {
+		expect = nil
+	}
+	chain = append(chain, "WithExpectStatus("+strings.Join(expect, ", ")+")")
+	// error responses of different types can not be decoded into one value, HTTPError.Body is left for caller
+	if len(errorTypes) == 1 {
+		chain = append(chain, fmt.Sprintf("WithErrorResult(new(%s))", errorTypes[0]))
+		if token.IsIdentifier(errorTypes[0]) {
+			g.errorTypes[errorTypes[0]] = true
+			g.imports["errors"] = true
+		}
+	}
+
+	// method
+	buf := new(bytes.Buffer)
+	summary := op.Summary
+	if summary == "" {
+		summary = "send " + mo.method + " " + path
+	}
+	buf.WriteString(comment(name, summary))
+	if op.Description != "" {
+		buf.WriteString("//\n" + comment("", op.Description))
+	}
+	fmt.Fprintf(buf, "//\n//\t%s %s\n", mo.method, path)
+	if op.Deprecated {
+		buf.WriteString("//\n// Deprecated: operation is deprecated in spec.\n")
+	}
+	returns, zero := "error", ""
+	if resultType != "" {
+		if g.structs[resultType] {
+			returns, zero = "(*"+resultType+", error)", "nil, "
+		} else if g.nillable(resultType) {
+			returns, zero = "("+resultType+", error)", "nil, "
+		} else {
+			returns, zero = "("+resultType+", error)", "res, "
+		}
+	}
+	fmt.Fprintf(buf, "func (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), returns)
+	fmt.Fprintf(buf, "\treq := c.newRequest(ctx, http.%s, %q)", httpMethodConst(mo.method), tmpl)
+	for _, v := range chain {
+		buf.WriteString(".\n\t\t" + v)
+	}
+	buf.WriteString("\n")
+	if len(fields) > 0 {
+		buf.WriteString(g.applyParams(fields))
+	}
+	switch {
+	case resultType == "":
+		buf.WriteString("\treturn req.Unmarshal(nil)\n")
+	case g.structs[resultType]:
+		fmt.Fprintf(buf, "\tres := new(%s)\n\tif err := req.Unmarshal(res); err != nil {\n\t\treturn %serr\n\t}\n\treturn res, nil\n", resultType, zero)
+	default:
+		fmt.Fprintf(buf, "\tvar res %s\n\tif err := req.Unmarshal(&res); err != nil {\n\t\treturn %serr\n\t}\n\treturn res, nil\n", resultType, zero)
+	}
+	buf.WriteString("}\n")
+	return buf.String(), g.err
+}
+
+type paramField struct {
+	*parameter
+	field string // go field name
+	typ   string // go type of field
+}
+
+// declareParams declare struct of query, header and cookie params, query params are encoded by WithQueryStruct
+func (g *generator) declareParams(name, method string, params []*parameter) (string, []paramField) {
+	g.decls[name] = ""
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// %s is the query, header and cookie params of %s\n", name, method)
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	var (
+		fields = []paramField{}
+		names  = map[string]bool{}
+	)
+	for _, p := range params {
+		field := uniqueField(goName(p.Name), names)
+		typ := g.goType(p.Schema, name+field)
+		if !p.Required && !g.nillable(typ) {
+			typ = "*" + typ
+		}
+		var tag string
+		switch p.In {
+		case "query":
+			tag = p.Name
+			if !p.Required {
+				tag += ",omitempty"
+			}
+			if strings.HasPrefix(typ, "[]") && p.Explode != nil && !*p.Explode {
+				tag += ",comma"
+			}
+			tag = fmt.Sprintf("query:%q", tag)
+		default:
+			tag = fmt.Sprintf("%s:%q", p.In, p.Name)
+		}
+		buf.WriteString(comment("", p.Description))
+		fmt.Fprintf(buf, "\t%s %s `%s`\n", field, typ, tag)
+		fields = append(fields, paramField{parameter: p, field: field, typ: typ})
+	}
+	buf.WriteString("}\n")
+	g.decls[name] = buf.String()
+	return name, fields
+}
+
+// applyParams return code which set params to req
+func (g *generator) applyParams(fields []paramField) string {
+	buf := new(bytes.Buffer)
+	buf.WriteString("\tif params != nil {\n")
+	for _, f := range fields {
+		if f.In == "query" {
+			buf.WriteString("\t\treq.WithQueryStruct(params)\n")
+			break
+		}
+	}
+	for _, f := range fields {
+		if f.In == "query" {
+			continue
+		}
+		header, prefix := f.Name, ""
+		if f.In == "cookie" {
+			header, prefix = "Cookie", f.Name+"="
+		}
+		expr, typ, indent := "params."+f.field, f.typ, "\t\t"
+		switch {
+		case strings.HasPrefix(typ, "[]") && typ != "[]byte":
+			fmt.Fprintf(buf, "\t\tfor _, v := range %s {\n", expr)
+			expr, typ, indent = "v", strings.TrimPrefix(typ, "[]"), "\t\t\t"
+		case strings.HasPrefix(typ, "*"):
+			fmt.Fprintf(buf, "\t\tif %s != nil {\n", expr)
+			expr, typ, indent = "*"+expr, strings.TrimPrefix(typ, "*"), "\t\t\t"
+		}
+		value := g.toString(expr, typ)
+		if prefix != "" {
+			value = strconv.Quote(prefix) + "+" + value
+		}
+		fmt.Fprintf(buf, "%sreq.WithHeader(%q, %s)\n", indent, header, value)
+		if indent != "\t\t" {
+			buf.WriteString("\t\t}\n")
+		}
+	}
+	buf.WriteString("\t}\n")
+	return buf.String()
+}
+
+// toString return code which convert expr of typ to string
+func (g *generator) toString(expr, typ string) string {
+	switch {
+	case typ == "string":
+		return expr
+	case typ == "time.Time":
+		return expr + ".Format(time.RFC3339)"
+	case typ == "[]byte":
+		return "string(" + expr + ")"
+	}
+	if g.stringTypes[typ] {
+		return "string(" + expr + ")"
+	}
+	g.imports["fmt"] = true
+	return "fmt.Sprint(" + expr + ")"
+}
+
+// pickContent return json content if exists, or the first content type
+func pickContent(content map[string]*mediaType) (string, *mediaType) {
+	keys := sortedKeys(content)
+	for _, k := range keys {
+		if isJSON(k) {
+			return k, content[k]
+		}
+	}
+	if len(keys) == 0 {
+		return "", nil
+	}
+	return keys[0], content[keys[0]]
+}
+
+func isJSON(contentType string) bool {
+	contentType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
+func httpMethodConst(method string) string {
+	return "Method" + method[:1] + strings.ToLower(method[1:])
+}
+
+// comment return doc comment of text, name is prepended to the first line
+func comment(name, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	if name != "" {
+		text = name + " " + strings.ToLower(text[:1]) + text[1:]
+	}
+	buf := new(bytes.Buffer)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			buf.WriteString("//\n")
+		} else {
+			buf.WriteString("// " + line + "\n")
+		}
+	}
+	return buf.String()
+}
+
+// names
+
+// words split s into words by non alphanumeric chars and camel case, e.g. X-Request-ID, showPetById
+func words(s string) []string {
+	var (
+		res   []string
+		cur   []rune
+		runes = []rune(s)
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			res = append(res, string(cur))
+			cur = nil
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(cur) > 0 {
+			prev := cur[len(cur)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return res
+}
+
+// goName return exported go name of s
+func goName(s string) string {
+	buf := new(strings.Builder)
+	for _, w := range words(s) {
+		upper := strings.ToUpper(w)
+		if commonInitialisms[upper] {
+			buf.WriteString(upper)
+			continue
+		}
+		if plural := strings.TrimSuffix(upper, "S"); plural != upper && commonInitialisms[plural] {
+			buf.WriteString(plural + "s") // e.g. URLs, IDs
+			continue
+		}
+		runes := []rune(w)
+		buf.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+	}
+	res := buf.String()
+	if res != "" && unicode.IsDigit([]rune(res)[0]) {
+		res = "N" + res
+	}
+	return res
+}
+
+// lowerName return unexported go name of s, used as argument name
+func lowerName(s string) string {
+	ws := words(s)
+	if len(ws) == 0 {
+		return "arg"
+	}
+	res := strings.ToLower(ws[0]) + goName(strings.Join(ws[1:], " "))
+	if unicode.IsDigit([]rune(res)[0]) {
+		res = "n" + res
+	}
+	if token.IsKeyword(res) {
+		res += "Param"
+	}
+	return res
+}
+
+// uniqueField return name, or name with number suffix if it's used
+func uniqueField(name string, used map[string]bool) string {
+	res := name
+	for i := 2; used[res]; i++ {
+		res = name + strconv.Itoa(i)
+	}
+	used[res] = true
+	return res
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+// Code generated by gorequests-gen from petstore.yaml. DO NOT EDIT.
+
+// Package petstore is the client of Swagger Petstore 1.0.0.
+package petstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jloha/gorequests"
+)
+
+// DefaultBaseURL is the url of the first server in spec
+const DefaultBaseURL = "http://petstore.swagger.io/v1"
+
+// Client send requests of operations in spec
+type Client struct {
+	baseURL   string
+	requester gorequests.Requester
+}
+
+// NewClient create client, DefaultBaseURL is used if baseURL is empty,
+// requests are created by requester, which is gorequests.NewFactory() if nil
+func NewClient(baseURL string, requester gorequests.Requester) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if requester == nil {
+		requester = gorequests.NewFactory()
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), requester: requester}
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string) *gorequests.Request {
+	return c.requester.New(method, c.baseURL+path).WithContext(ctx)
+}
+
+// ListPets list all pets
+//
+//	GET /pets
+func (c *Client) ListPets(ctx context.Context, params *ListPetsParams) (Pets, error) {
+	req := c.newRequest(ctx, http.MethodGet, "/pets").
+		WithExpectStatus(200).
+		WithErrorResult(new(Error))
+	if params != nil {
+		req.WithQueryStruct(params)
+		if params.XRequestID != nil {
+			req.WithHeader("X-Request-ID", *params.XRequestID)
+		}
+	}
+	var res Pets
+	if err := req.Unmarshal(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreatePet create a pet
+//
+//	POST /pets
+func (c *Client) CreatePet(ctx context.Context, body *NewPet, params *CreatePetParams) (*Pet, error) {
+	req := c.newRequest(ctx, http.MethodPost, "/pets").
+		WithJSON(body).
+		WithExpectStatus(201).
+		WithErrorResult(new(Error))
+	if params != nil {
+		if params.XRequestID != nil {
+			req.WithHeader("X-Request-ID", *params.XRequestID)
+		}
+	}
+	res := new(Pet)
+	if err := req.Unmarshal(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ShowPetByID info for a specific pet
+//
+//	GET /pets/{petId}
+func (c *Client) ShowPetByID(ctx context.Context, petID int64) (*Pet, error) {
+	req := c.newRequest(ctx, http.MethodGet, "/pets/{petId}").
+		WithPathParam("petId", fmt.Sprint(petID)).
+		WithExpectStatus(200).
+		WithErrorResult(new(Error))
+	res := new(Pet)
+	if err := req.Unmarshal(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeletePet send DELETE /pets/{petId}
+//
+//	DELETE /pets/{petId}
+func (c *Client) DeletePet(ctx context.Context, petID int64, params *DeletePetParams) error {
+	req := c.newRequest(ctx, http.MethodDelete, "/pets/{petId}").
+		WithPathParam("petId", fmt.Sprint(petID)).
+		WithExpectStatus(204).
+		WithErrorResult(new(Error))
+	if params != nil {
+		req.WithQueryStruct(params)
+	}
+	return req.Unmarshal(nil)
+}
+
+// UpdatePet send PATCH /pets/{petId}
+//
+//	PATCH /pets/{petId}
+//
+// Deprecated: operation is deprecated in spec.
+func (c *Client) UpdatePet(ctx context.Context, petID int64, body *UpdatePetRequest) (*Pet, error) {
+	req := c.newRequest(ctx, http.MethodPatch, "/pets/{petId}").
+		WithPathParam("petId", fmt.Sprint(petID)).
+		WithJSON(body).
+		WithExpectStatus(200).
+		WithErrorResult(new(Error))
+	res := new(Pet)
+	if err := req.Unmarshal(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetStoresByStoreIDInventory send GET /stores/{storeId}/inventory
+//
+//	GET /stores/{storeId}/inventory
+func (c *Client) GetStoresByStoreIDInventory(ctx context.Context, storeID string) (*GetStoresByStoreIDInventoryResponse, error) {
+	req := c.newRequest(ctx, http.MethodGet, "/stores/{storeId}/inventory").
+		WithPathParam("storeId", storeID).
+		WithExpectStatus(200)
+	res := new(GetStoresByStoreIDInventoryResponse)
+	if err := req.Unmarshal(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreatePetParams is the query, header and cookie params of CreatePet
+type CreatePetParams struct {
+	XRequestID *string `header:"X-Request-ID"`
+}
+
+// DeletePetParams is the query, header and cookie params of DeletePet
+type DeletePetParams struct {
+	Force bool `query:"force"`
+}
+
+type Error struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+type GetStoresByStoreIDInventoryResponse struct {
+	Counts    map[string]int32 `json:"counts"`
+	UpdatedAt *time.Time       `json:"updatedAt,omitempty"`
+}
+
+// ListPetsParams is the query, header and cookie params of ListPets
+type ListPetsParams struct {
+	XRequestID *string `header:"X-Request-ID"`
+	// How many items to return at one time (max 100)
+	Limit     *int32      `query:"limit,omitempty"`
+	Tags      []string    `query:"tags,omitempty"`
+	Status    []PetStatus `query:"status,omitempty,comma"`
+	BornAfter *time.Time  `query:"bornAfter,omitempty"`
+}
+
+type NewPet struct {
+	// Name of the pet
+	Name      string       `json:"name"`
+	Owner     *NewPetOwner `json:"owner,omitempty"`
+	PhotoURLs []string     `json:"photoUrls,omitempty"`
+	Status    *PetStatus   `json:"status,omitempty"`
+	Tag       *string      `json:"tag,omitempty"`
+}
+
+type NewPetOwner struct {
+	Email *string `json:"email,omitempty"`
+	Name  *string `json:"name,omitempty"`
+}
+
+type Pet struct {
+	ID int64 `json:"id"`
+	// Name of the pet
+	Name      string       `json:"name"`
+	Owner     *NewPetOwner `json:"owner,omitempty"`
+	PhotoURLs []string     `json:"photoUrls,omitempty"`
+	Status    *PetStatus   `json:"status,omitempty"`
+	Tag       *string      `json:"tag,omitempty"`
+}
+
+// PetStatus status of the pet in the store
+type PetStatus string
+
+const (
+	PetStatusAvailable PetStatus = "available"
+	PetStatusPending   PetStatus = "pending"
+	PetStatusSold      PetStatus = "sold"
+)
+
+type Pets []Pet
+
+type UpdatePetRequest struct {
+	Name   *string    `json:"name,omitempty"`
+	Status *PetStatus `json:"status,omitempty"`
+}
+
+type ValidationError struct {
+	Extra  json.RawMessage   `json:"extra,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// AsError return Error decoded from error response body, if err is *gorequests.HTTPError
+func AsError(err error) (*Error, bool) {
+	var httpErr *gorequests.HTTPError
+	if !errors.As(err, &httpErr) {
+		return nil, false
+	}
+	res, ok := httpErr.Result.(*Error)
+	return res, ok
+}
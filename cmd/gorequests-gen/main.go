@@ -0,0 +1,66 @@
+// Command gorequests-gen generate go client package from OpenAPI 3 document in json or yaml format.
+//
+//	gorequests-gen -spec openapi.yaml -package petstore -o client.go
+//
+// generated package contains:
+//
+//	Client                  created by NewClient(baseURL, requester), requester is *gorequests.Factory or *gorequests.Session
+//	method per operation    named by operationId, path params are arguments, request body is body argument
+//	XxxParams               query, header and cookie params of operation, query params are encoded by WithQueryStruct
+//	types of schemas        components and inline schemas, enum of string is declared with constants
+//	AsXxx(err)              typed error body decoded by WithErrorResult, when error responses of operation share one schema
+//
+// with go:generate:
+//
+//	//go:generate go run github.com/jloha/gorequests/cmd/gorequests-gen -spec openapi.yaml -package petstore -o client.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("gorequests-gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	spec := fs.String("spec", "", "path of OpenAPI 3 document, json or yaml")
+	pkg := fs.String("package", "client", "package name of generated code")
+	out := fs.String("o", "", "output file, stdout if empty")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *spec == "" || fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: gorequests-gen -spec openapi.yaml [-package name] [-o client.go]")
+		fs.PrintDefaults()
+		return 2
+	}
+
+	bs, err := ioutil.ReadFile(*spec)
+	if err != nil {
+		fmt.Fprintln(stderr, "gorequests-gen:", err)
+		return 1
+	}
+	code, err := generate(bs, *pkg, filepath.Base(*spec))
+	if err != nil {
+		fmt.Fprintln(stderr, "gorequests-gen:", err)
+		return 1
+	}
+	if *out == "" {
+		_, err = stdout.Write(code)
+	} else {
+		err = ioutil.WriteFile(*out, code, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "gorequests-gen:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jloha/gorequests"
+	"github.com/jloha/gorequests/cmd/gorequests-gen/internal/petstore"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenFile is generated from testdata/petstore.yaml, and compiled with the module
+const goldenFile = "internal/petstore/client.go"
+
+func Test_Generate(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("golden", func(t *testing.T) {
+		spec, err := ioutil.ReadFile("testdata/petstore.yaml")
+		as.Nil(err)
+		code, err := generate(spec, "petstore", "petstore.yaml")
+		as.Nil(err)
+		if *update {
+			as.Nil(ioutil.WriteFile(goldenFile, code, 0o644))
+		}
+		golden, err := ioutil.ReadFile(goldenFile)
+		as.Nil(err)
+		as.Equal(string(golden), string(code), "run go test -update to update golden file")
+	})
+
+	t.Run("json spec", func(t *testing.T) {
+		code, err := generate([]byte(`{
+			"openapi": "3.1.0",
+			"info": {"title": "Users", "version": "v1"},
+			"paths": {"/users/{user-id}": {"get": {
+				"parameters": [
+					{"name": "user-id", "in": "path", "required": true, "schema": {"type": "string"}},
+					{"name": "session", "in": "cookie", "schema": {"type": "string"}}
+				],
+				"responses": {"2XX": {"description": "user", "content": {"application/json": {"schema": {
+					"type": "object", "properties": {"name": {"type": ["string", "null"]}}
+				}}}}}
+			}}}
+		}`), "users", "users.json")
+		as.Nil(err)
+		s := string(code)
+		as.Contains(s, "func (c *Client) GetUsersByUserID(ctx context.Context, userID string, params *GetUsersByUserIDParams) (*GetUsersByUserIDResponse, error) {")
+		as.Contains(s, `c.newRequest(ctx, http.MethodGet, "/users/{user_id}").`)
+		as.Contains(s, `WithPathParam("user_id", userID).`)
+		as.Contains(s, "WithExpectStatus()\n")
+		as.Contains(s, `req.WithHeader("Cookie", "session="+*params.Session)`)
+		as.Contains(s, "Name *string `json:\"name,omitempty\"`")
+		as.Contains(s, `const DefaultBaseURL = ""`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for spec, errMsg := range map[string]string{
+			`swagger: "2.0"`: `unsupported openapi version ""`,
+			`openapi: 3.0.0
+paths:
+  /a:
+    get:
+      responses:
+        '200':
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Missing'`: `unresolved ref "#/components/schemas/Missing"`,
+			`openapi: 3.0.0
+paths:
+  /a/{id}:
+    get:
+      responses: {}`: "GET /a/{id}: path param id is not defined",
+			`openapi: 3.0.0
+paths:
+  /a:
+    get:
+      operationId: get
+      responses: {}
+  /b:
+    get:
+      operationId: get
+      responses: {}`: "duplicate operation name Get",
+		} {
+			_, err := generate([]byte(spec), "client", "spec.yaml")
+			if as.NotNil(err, spec) {
+				as.Contains(err.Error(), errMsg)
+			}
+		}
+	})
+
+	t.Run("names", func(t *testing.T) {
+		for s, want := range map[string]string{
+			"showPetById":  "ShowPetByID",
+			"X-Request-ID": "XRequestID",
+			"photoUrls":    "PhotoURLs",
+			"HTTPServer":   "HTTPServer",
+			"snake_case":   "SnakeCase",
+			"200":          "N200",
+		} {
+			as.Equal(want, goName(s), s)
+		}
+		as.Equal("typeParam", lowerName("type"))
+		as.Equal("userID", lowerName("user-id"))
+	})
+
+	t.Run("run", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "client.go")
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		as.Equal(0, run([]string{"-spec", "testdata/petstore.yaml", "-package", "petstore", "-o", out}, stdout, stderr))
+		bs, err := ioutil.ReadFile(out)
+		as.Nil(err)
+		golden, _ := ioutil.ReadFile(goldenFile)
+		as.Equal(string(golden), string(bs))
+
+		as.Equal(2, run(nil, stdout, stderr))
+		as.Equal(1, run([]string{"-spec", "testdata/missing.yaml"}, stdout, stderr))
+	})
+}
+
+func Test_GeneratedClient(t *testing.T) {
+	as := assert.New(t)
+	var lastRequest *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /v1/pets":
+			_, _ = w.Write([]byte(`[{"id": 1, "name": "kitty", "status": "available"}]`))
+		case "POST /v1/pets":
+			body := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			body["id"] = 2
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(body)
+		case "DELETE /v1/pets/2":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code": 404, "message": "pet not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := petstore.NewClient(server.URL+"/v1/", gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger())))
+
+	t.Run("query and header", func(t *testing.T) {
+		limit, requestID := int32(10), "req-1"
+		pets, err := client.ListPets(ctx, &petstore.ListPetsParams{
+			Limit:      &limit,
+			Tags:       []string{"a", "b"},
+			Status:     []petstore.PetStatus{petstore.PetStatusAvailable, petstore.PetStatusSold},
+			BornAfter:  func() *time.Time { v := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC); return &v }(),
+			XRequestID: &requestID,
+		})
+		as.Nil(err)
+		as.Equal(petstore.Pets{{ID: 1, Name: "kitty", Status: func() *petstore.PetStatus { v := petstore.PetStatusAvailable; return &v }()}}, pets)
+		as.Equal("bornAfter=2020-01-02T00%3A00%3A00Z&limit=10&status=available%2Csold&tags=a&tags=b", lastRequest.URL.RawQuery)
+		as.Equal("req-1", lastRequest.Header.Get("X-Request-ID"))
+
+		_, err = client.ListPets(ctx, nil)
+		as.Nil(err)
+		as.Equal("", lastRequest.URL.RawQuery)
+	})
+
+	t.Run("body and path", func(t *testing.T) {
+		tag := "cat"
+		pet, err := client.CreatePet(ctx, &petstore.NewPet{Name: "kitty", Tag: &tag}, nil)
+		as.Nil(err)
+		as.Equal(&petstore.Pet{ID: 2, Name: "kitty", Tag: &tag}, pet)
+
+		as.Nil(client.DeletePet(ctx, 2, &petstore.DeletePetParams{Force: false}))
+		as.Equal("/v1/pets/2", lastRequest.URL.Path)
+		as.Equal("force=false", lastRequest.URL.RawQuery)
+	})
+
+	t.Run("typed error", func(t *testing.T) {
+		_, err := client.ShowPetByID(ctx, 3)
+		as.NotNil(err)
+		as.Equal("/v1/pets/3", lastRequest.URL.Path)
+		as.Equal(gorequests.KindStatus, gorequests.KindOf(err))
+		res, ok := petstore.AsError(err)
+		as.True(ok)
+		as.Equal(&petstore.Error{Code: 404, Message: "pet not found"}, res)
+
+		_, ok = petstore.AsError(context.Canceled)
+		as.False(ok)
+	})
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is the subset of OpenAPI 3 document used by generator
+type document struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]*pathItem `json:"paths"`
+	Components struct {
+		Schemas       map[string]*schema      `json:"schemas"`
+		Parameters    map[string]*parameter   `json:"parameters"`
+		RequestBodies map[string]*requestBody `json:"requestBodies"`
+		Responses     map[string]*response    `json:"responses"`
+	} `json:"components"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `json:"parameters"`
+	Get        *operation   `json:"get"`
+	Put        *operation   `json:"put"`
+	Post       *operation   `json:"post"`
+	Delete     *operation   `json:"delete"`
+	Options    *operation   `json:"options"`
+	Head       *operation   `json:"head"`
+	Patch      *operation   `json:"patch"`
+	Trace      *operation   `json:"trace"`
+}
+
+type methodOperation struct {
+	method string
+	op     *operation
+}
+
+// operations return operations of path item in fixed order
+func (p *pathItem) operations() []methodOperation {
+	res := []methodOperation{}
+	for _, v := range []methodOperation{
+		{"GET", p.Get}, {"PUT", p.Put}, {"POST", p.Post}, {"DELETE", p.Delete},
+		{"OPTIONS", p.Options}, {"HEAD", p.Head}, {"PATCH", p.Patch}, {"TRACE", p.Trace},
+	} {
+		if v.op != nil {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+type operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Deprecated  bool                 `json:"deprecated"`
+	Parameters  []*parameter         `json:"parameters"`
+	RequestBody *requestBody         `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Explode     *bool   `json:"explode"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Required    bool                  `json:"required"`
+	Content     map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 schemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	AllOf                []*schema          `json:"allOf"`
+	OneOf                []*schema          `json:"oneOf"`
+	AnyOf                []*schema          `json:"anyOf"`
+}
+
+// additional return schema of additionalProperties, nil if it's not set or false
+func (s *schema) additional() *schema {
+	raw := strings.TrimSpace(string(s.AdditionalProperties))
+	switch raw {
+	case "", "false", "null":
+		return nil
+	case "true":
+		return &schema{}
+	}
+	res := new(schema)
+	if err := json.Unmarshal(s.AdditionalProperties, res); err != nil {
+		return &schema{}
+	}
+	return res
+}
+
+// schemaType is type of schema, type array of OpenAPI 3.1 like ["string", "null"] is reduced to the first non-null one
+type schemaType struct {
+	Name     string
+	Nullable bool
+}
+
+func (t *schemaType) UnmarshalJSON(bs []byte) error {
+	var name string
+	if err := json.Unmarshal(bs, &name); err == nil {
+		t.Name = name
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(bs, &names); err != nil {
+		return fmt.Errorf("invalid schema type %s", bs)
+	}
+	for _, v := range names {
+		if v == "null" {
+			t.Nullable = true
+		} else if t.Name == "" {
+			t.Name = v
+		}
+	}
+	return nil
+}
+
+// parseDocument parse OpenAPI 3 document in json or yaml format
+func parseDocument(bs []byte) (*document, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(bs, &raw); err != nil {
+		return nil, fmt.Errorf("parse document: %w", err)
+	}
+	bs, err := json.Marshal(normalizeYAML(raw))
+	if err != nil {
+		return nil, fmt.Errorf("parse document: %w", err)
+	}
+	doc := new(document)
+	if err := json.Unmarshal(bs, doc); err != nil {
+		return nil, fmt.Errorf("parse document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported openapi version %q, only 3.x is supported", doc.OpenAPI)
+	}
+	return doc, nil
+}
+
+// normalizeYAML convert map keys to string, e.g. unquoted status code 200 of responses
+func normalizeYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalizeYAML(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			res[fmt.Sprint(k)] = normalizeYAML(item)
+		}
+		return res
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// resolve* follow local $ref of components, like #/components/schemas/Pet
+
+func (d *document) resolveSchema(s *schema) (*schema, error) {
+	for depth := 0; s != nil && s.Ref != ""; depth++ {
+		name, err := refName(s.Ref, "schemas")
+		if err != nil {
+			return nil, err
+		}
+		next, ok := d.Components.Schemas[name]
+		if !ok || depth > 32 {
+			return nil, fmt.Errorf("unresolved ref %q", s.Ref)
+		}
+		s = next
+	}
+	return s, nil
+}
+
+func (d *document) resolveParameter(p *parameter) (*parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, err := refName(p.Ref, "parameters")
+	if err != nil {
+		return nil, err
+	}
+	if res, ok := d.Components.Parameters[name]; ok && res.Ref == "" {
+		return res, nil
+	}
+	return nil, fmt.Errorf("unresolved ref %q", p.Ref)
+}
+
+func (d *document) resolveRequestBody(b *requestBody) (*requestBody, error) {
+	if b == nil || b.Ref == "" {
+		return b, nil
+	}
+	name, err := refName(b.Ref, "requestBodies")
+	if err != nil {
+		return nil, err
+	}
+	if res, ok := d.Components.RequestBodies[name]; ok && res.Ref == "" {
+		return res, nil
+	}
+	return nil, fmt.Errorf("unresolved ref %q", b.Ref)
+}
+
+func (d *document) resolveResponse(r *response) (*response, error) {
+	if r == nil || r.Ref == "" {
+		return r, nil
+	}
+	name, err := refName(r.Ref, "responses")
+	if err != nil {
+		return nil, err
+	}
+	if res, ok := d.Components.Responses[name]; ok && res.Ref == "" {
+		return res, nil
+	}
+	return nil, fmt.Errorf("unresolved ref %q", r.Ref)
+}
+
+// refName return component name of local ref, only refs in the same document are supported
+func refName(ref, kind string) (string, error) {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("unsupported ref %q, should start with %s", ref, prefix)
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(ref[len(prefix):], "~1", "/"), "~0", "~"), nil
+}
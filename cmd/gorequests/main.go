@@ -0,0 +1,353 @@
+// Command gorequests is a httpie style http client built on gorequests.
+//
+//	gorequests [flags] [METHOD] URL [ITEM ...]
+//
+// items:
+//
+//	Header:value     request header
+//	key==value       query param
+//	key=value        json string field, or form field with --form
+//	key:=json        raw json field, e.g. age:=18 tags:='["a"]'
+//	key@path         file upload, implies --form
+//
+// method is GET without data items, and POST with data items.
+//
+// exit code is 0 for 2xx, 3 for 3xx, 4 for 4xx, 5 for 5xx, 1 for request error and 2 for usage error.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jloha/gorequests"
+)
+
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitRedirect = 3
+	exitClient   = 4
+	exitServer   = 5
+)
+
+var methodRegexp = regexp.MustCompile(`^[A-Z]+$`)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+type options struct {
+	form       bool
+	session    string
+	timeout    time.Duration
+	noRedirect bool
+	insecure   bool
+	printHead  bool
+	printBody  bool
+	verbose    bool
+	raw        bool
+}
+
+type item struct {
+	kind  string // header, query, field, json, file
+	key   string
+	value string
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	opts := options{}
+	var headersOnly, bodyOnly bool
+	fs := flag.NewFlagSet("gorequests", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.BoolVar(&opts.form, "form", false, "send data items as form fields")
+	fs.BoolVar(&opts.form, "f", false, "shorthand of --form")
+	fs.StringVar(&opts.session, "session", "", "named session, or path of cookie file if it contains /")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "request timeout")
+	fs.BoolVar(&opts.noRedirect, "no-redirect", false, "do not follow redirect")
+	fs.BoolVar(&opts.insecure, "insecure", false, "skip tls certificate verify")
+	fs.BoolVar(&opts.insecure, "k", false, "shorthand of --insecure")
+	fs.BoolVar(&headersOnly, "headers", false, "print response headers only")
+	fs.BoolVar(&bodyOnly, "body", false, "print response body only")
+	fs.BoolVar(&opts.verbose, "v", false, "print request and response")
+	fs.BoolVar(&opts.raw, "raw", false, "print body as is, without json pretty print")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gorequests [flags] [METHOD] URL [ITEM ...]")
+		fs.PrintDefaults()
+	}
+
+	// flags can be mixed with positional args
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return exitUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	opts.printHead = (headersOnly || opts.verbose) && !bodyOnly
+	opts.printBody = bodyOnly || !headersOnly
+
+	if len(positional) == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	method := ""
+	if methodRegexp.MatchString(positional[0]) && len(positional) > 1 {
+		method, positional = positional[0], positional[1:]
+	}
+	rawURL := positional[0]
+	if !strings.Contains(rawURL, "://") {
+		if strings.HasPrefix(rawURL, ":") {
+			rawURL = "localhost" + rawURL
+		}
+		rawURL = "http://" + rawURL
+	}
+	items := make([]item, 0, len(positional)-1)
+	for _, v := range positional[1:] {
+		it, err := parseItem(v)
+		if err != nil {
+			fmt.Fprintln(stderr, "gorequests:", err)
+			return exitUsage
+		}
+		items = append(items, it)
+	}
+
+	req, err := buildRequest(method, rawURL, items, &opts)
+	if err != nil {
+		fmt.Fprintln(stderr, "gorequests:", err)
+		return exitUsage
+	}
+	if opts.verbose {
+		printRequest(stdout, req)
+	}
+
+	resp, err := req.Response()
+	if err != nil {
+		fmt.Fprintln(stderr, "gorequests:", err)
+		return exitError
+	}
+	body, err := req.Bytes()
+	if err != nil {
+		fmt.Fprintln(stderr, "gorequests:", err)
+		return exitError
+	}
+	if opts.printHead {
+		printResponseHead(stdout, resp)
+	}
+	if opts.printBody && len(body) > 0 {
+		if !opts.raw {
+			body = prettyJSON(body)
+		}
+		fmt.Fprintln(stdout, strings.TrimRight(string(body), "\n"))
+	}
+	return exitCode(resp.StatusCode)
+}
+
+// parseItem parse item, separator which appears first wins, like httpie
+func parseItem(s string) (item, error) {
+	seps := []struct {
+		sep, kind string
+	}{
+		{"==", "query"}, {":=", "json"}, {"=", "field"}, {"@", "file"}, {":", "header"},
+	}
+	pos, res := -1, item{}
+	for _, v := range seps {
+		idx := strings.Index(s, v.sep)
+		if idx <= 0 {
+			continue
+		}
+		// separator listed first wins at same position, e.g. == over =, := over :
+		if pos < 0 || idx < pos {
+			pos, res = idx, item{kind: v.kind, key: s[:idx], value: s[idx+len(v.sep):]}
+		}
+	}
+	if pos < 0 {
+		return item{}, fmt.Errorf("invalid item %q", s)
+	}
+	return res, nil
+}
+
+func buildRequest(method, rawURL string, items []item, opts *options) (*gorequests.Request, error) {
+	var (
+		header = http.Header{}
+		query  = url.Values{}
+		fields = map[string]interface{}{}
+		keys   []string
+		files  [][2]string
+	)
+	for _, it := range items {
+		switch it.kind {
+		case "header":
+			header.Add(it.key, it.value)
+		case "query":
+			query.Add(it.key, it.value)
+		case "field":
+			fields[it.key] = it.value
+			keys = append(keys, it.key)
+		case "json":
+			var v interface{}
+			if err := json.Unmarshal([]byte(it.value), &v); err != nil {
+				return nil, fmt.Errorf("invalid json of %s: %w", it.key, err)
+			}
+			fields[it.key] = v
+			keys = append(keys, it.key)
+		case "file":
+			files = append(files, [2]string{it.key, it.value})
+		}
+	}
+	if len(files) > 0 {
+		opts.form = true
+	}
+	hasData := len(keys) > 0 || len(files) > 0
+	if method == "" {
+		method = http.MethodGet
+		if hasData {
+			method = http.MethodPost
+		}
+	}
+
+	var req *gorequests.Request
+	if opts.session != "" {
+		req = gorequests.NewSession(sessionFile(opts.session)).New(method, rawURL)
+	} else {
+		req = gorequests.New(method, rawURL)
+	}
+	req.WithLogger(gorequests.NewDiscardLogger()).
+		WithTimeout(opts.timeout).
+		WithRedirect(!opts.noRedirect).
+		WithIgnoreSSL(opts.insecure)
+	for k, vs := range query {
+		for _, v := range vs {
+			req.WithQuery(k, v)
+		}
+	}
+
+	switch {
+	case !hasData:
+	case len(files) > 0:
+		contentType, body, err := multipartBody(fields, keys, files)
+		if err != nil {
+			return nil, err
+		}
+		req.WithBody(body).WithHeader("Content-Type", contentType)
+	case opts.form:
+		values := url.Values{}
+		for _, k := range keys {
+			values.Set(k, fmt.Sprint(fields[k]))
+		}
+		req.WithBody(values.Encode()).WithHeader("Content-Type", "application/x-www-form-urlencoded")
+	default:
+		req.WithJSON(fields)
+	}
+	if hasData && !opts.form && header.Get("Accept") == "" {
+		req.WithHeader("Accept", "application/json, */*;q=0.5")
+	}
+	for k, vs := range header {
+		req.RequestHeader().Del(k) // header item override default one
+		for _, v := range vs {
+			req.WithHeader(k, v)
+		}
+	}
+	return req, nil
+}
+
+func multipartBody(fields map[string]interface{}, keys []string, files [][2]string) (string, []byte, error) {
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+	for _, k := range keys {
+		if err := w.WriteField(k, fmt.Sprint(fields[k])); err != nil {
+			return "", nil, err
+		}
+	}
+	for _, f := range files {
+		bs, err := ioutil.ReadFile(f[1])
+		if err != nil {
+			return "", nil, err
+		}
+		part, err := w.CreateFormFile(f[0], filepath.Base(f[1]))
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err = part.Write(bs); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", nil, err
+	}
+	return w.FormDataContentType(), buf.Bytes(), nil
+}
+
+// sessionFile return cookie file of named session, name which contains path separator is used as file path
+func sessionFile(name string) string {
+	if strings.ContainsAny(name, `/\`) {
+		return name
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	dir = filepath.Join(dir, "gorequests", "sessions")
+	_ = os.MkdirAll(dir, 0o700)
+	return filepath.Join(dir, name+".json")
+}
+
+func printRequest(w io.Writer, req *gorequests.Request) {
+	curl, err := req.ToCurl(gorequests.WithCurlRedact())
+	if err == nil {
+		fmt.Fprintf(w, "> %s\n\n", curl)
+	}
+}
+
+func printResponseHead(w io.Writer, resp *http.Response) {
+	fmt.Fprintf(w, "%s %s\n", resp.Proto, resp.Status)
+	keys := make([]string, 0, len(resp.Header))
+	for k := range resp.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range resp.Header[k] {
+			fmt.Fprintf(w, "%s: %s\n", k, v)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func prettyJSON(body []byte) []byte {
+	buf := new(bytes.Buffer)
+	if err := json.Indent(buf, body, "", "    "); err != nil {
+		return body
+	}
+	return buf.Bytes()
+}
+
+func exitCode(status int) int {
+	switch {
+	case status >= 500:
+		return exitServer
+	case status >= 400:
+		return exitClient
+	case status >= 300:
+		return exitRedirect
+	default:
+		return exitOK
+	}
+}
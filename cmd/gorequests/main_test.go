@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jloha/gorequests/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Run(t *testing.T) {
+	as := assert.New(t)
+	server := testserver.New()
+	defer server.Close()
+
+	exec := func(args ...string) (int, string, string) {
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		code := run(args, stdout, stderr)
+		return code, stdout.String(), stderr.String()
+	}
+	decode := func(s string) map[string]interface{} {
+		res := map[string]interface{}{}
+		as.Nil(json.Unmarshal([]byte(s), &res))
+		return res
+	}
+
+	t.Run("json", func(t *testing.T) {
+		code, stdout, _ := exec(server.URL+"/anything", "name=bob", "age:=18", "tags:=[\"a\"]", "page==2", "X-Token:t", "--timeout", "3s")
+		as.Equal(0, code)
+		as.Contains(stdout, "\n    \"method\": \"POST\"")
+		resp := decode(stdout)
+		as.Equal(map[string]interface{}{"name": "bob", "age": float64(18), "tags": []interface{}{"a"}}, resp["json"])
+		as.Equal("2", resp["args"].(map[string]interface{})["page"])
+		as.Equal("t", resp["headers"].(map[string]interface{})["X-Token"])
+		as.Equal("application/json", resp["headers"].(map[string]interface{})["Content-Type"])
+	})
+
+	t.Run("form and file", func(t *testing.T) {
+		code, stdout, _ := exec("--form", "PUT", server.URL+"/anything", "name=bob")
+		as.Equal(0, code)
+		resp := decode(stdout)
+		as.Equal("PUT", resp["method"])
+		as.Equal(map[string]interface{}{"name": "bob"}, resp["form"])
+
+		file := filepath.Join(t.TempDir(), "a.txt")
+		as.Nil(ioutil.WriteFile(file, []byte("content"), 0o644))
+		code, stdout, _ = exec(server.URL+"/post", "name=bob", "file@"+file)
+		as.Equal(0, code)
+		resp = decode(stdout)
+		as.Equal(map[string]interface{}{"file": "content"}, resp["files"])
+		as.Equal(map[string]interface{}{"name": "bob"}, resp["form"])
+	})
+
+	t.Run("status", func(t *testing.T) {
+		code, _, _ := exec(server.URL + "/status/404")
+		as.Equal(4, code)
+		code, _, _ = exec(server.URL + "/status/503")
+		as.Equal(5, code)
+
+		code, stdout, _ := exec("--no-redirect", "--headers", server.URL+"/redirect/1")
+		as.Equal(3, code)
+		as.True(strings.HasPrefix(stdout, "HTTP/1.1 302 Found\n"), stdout)
+		as.Contains(stdout, "Location: /get\n")
+
+		code, _, stderr := exec("http://127.0.0.1:1/get")
+		as.Equal(1, code)
+		as.NotEmpty(stderr)
+
+		code, _, _ = exec()
+		as.Equal(2, code)
+		code, _, _ = exec(server.URL, "invalid")
+		as.Equal(2, code)
+	})
+
+	t.Run("session", func(t *testing.T) {
+		session := filepath.Join(t.TempDir(), "session.json")
+		code, _, _ := exec("--session", session, server.URL+"/cookies/set", "a==b")
+		as.Equal(0, code)
+
+		code, stdout, _ := exec("--session", session, "--raw", server.URL+"/cookies")
+		as.Equal(0, code)
+		as.Equal(map[string]interface{}{"a": "b"}, decode(stdout)["cookies"])
+	})
+
+	t.Run("parse item", func(t *testing.T) {
+		for s, want := range map[string]item{
+			"a==b":          {kind: "query", key: "a", value: "b"},
+			"a=b==c":        {kind: "field", key: "a", value: "b==c"},
+			"a:=1":          {kind: "json", key: "a", value: "1"},
+			"X-Url:http://": {kind: "header", key: "X-Url", value: "http://"},
+			"mail=a@b.c":    {kind: "field", key: "mail", value: "a@b.c"},
+			"f@./a.txt":     {kind: "file", key: "f", value: "./a.txt"},
+		} {
+			it, err := parseItem(s)
+			as.Nil(err)
+			as.Equal(want, it, s)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Coalescer share one upstream call between identical in-flight GET and HEAD requests,
+// requests are identical if they have same method, full url, credentials and values of selected headers,
+// credentials are Authorization, Proxy-Authorization and Cookie headers, so users never share responses.
+//
+// every caller get its own copy of response header and body, and cancellation of one caller
+// does not affect others, upstream call is canceled only when all callers are canceled.
+type Coalescer struct {
+	headers []string
+	lock    sync.Mutex
+	calls   map[string]*coalesceCall
+}
+
+// coalesceCredentialHeaders are always part of the identity of request, and of cache key
+var coalesceCredentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// NewCoalescer create coalescer, headers are the request headers which are part of the identity of request
+// in addition to credential headers
+func NewCoalescer(headers ...string) *Coalescer {
+	canonical := append([]string(nil), coalesceCredentialHeaders...)
+	seen := map[string]bool{}
+	for _, v := range canonical {
+		seen[v] = true
+	}
+	for _, v := range headers {
+		if v = http.CanonicalHeaderKey(v); !seen[v] {
+			seen[v] = true
+			canonical = append(canonical, v)
+		}
+	}
+	return &Coalescer{headers: canonical, calls: map[string]*coalesceCall{}}
+}
+
+type coalesceCall struct {
+	done    chan struct{}
+	waiters int
+	cancel  context.CancelFunc
+
+	// result, read only after done is closed
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+func (c *Coalescer) key(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteString(" ")
+	b.WriteString(req.URL.String())
+	for _, name := range c.headers {
+		b.WriteString("\n")
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(strings.Join(req.Header.Values(name), ", "))
+	}
+	return b.String()
+}
+
+func (c *Coalescer) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) || (req.Body != nil && req.Body != http.NoBody) {
+		return next.RoundTrip(req)
+	}
+
+	key := c.key(req)
+	c.lock.Lock()
+	call, ok := c.calls[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(detachedContext{req.Context()})
+		call = &coalesceCall{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = call
+		go c.do(next, req.Clone(ctx), key, call)
+	}
+	call.waiters++
+	c.lock.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		return call.response(req), nil
+	case <-req.Context().Done():
+		c.lock.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// new callers should start a new call instead of joining the canceled one
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+			call.cancel()
+		}
+		c.lock.Unlock()
+		return nil, req.Context().Err()
+	}
+}
+
+func (c *Coalescer) do(next http.RoundTripper, req *http.Request, key string, call *coalesceCall) {
+	defer call.cancel()
+
+	call.resp, call.err = next.RoundTrip(req)
+	if call.err == nil {
+		call.body, call.err = ioutil.ReadAll(call.resp.Body)
+		_ = call.resp.Body.Close()
+	}
+
+	c.lock.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	c.lock.Unlock()
+	close(call.done)
+}
+
+// response copy shared response for one caller
+func (call *coalesceCall) response(req *http.Request) *http.Response {
+	resp := *call.resp
+	resp.Header = call.resp.Header.Clone()
+	resp.Trailer = call.resp.Trailer.Clone()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(call.body))
+	resp.ContentLength = int64(len(call.body))
+	resp.Request = req
+	return &resp
+}
+
+type coalesceTransport struct {
+	coalescer *Coalescer
+	next      http.RoundTripper
+}
+
+func (t *coalesceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.coalescer.roundTrip(t.next, req)
+}
+
+// detachedContext keep values of parent context, but never canceled with parent
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) { return }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
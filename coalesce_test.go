@@ -0,0 +1,170 @@
+package gorequests_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSlowServer(hits *int32, release chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(hits, 1)
+		<-release
+		w.Header().Set("X-Hit", strconv.Itoa(int(n)))
+		_, _ = w.Write([]byte(r.Header.Get("X-Tenant") + strconv.Itoa(int(n))))
+	}))
+}
+
+func Test_Coalescing(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("share", func(t *testing.T) {
+		var hits int32
+		release := make(chan struct{})
+		server := newSlowServer(&hits, release)
+		defer server.Close()
+		fac := gorequests.NewFactory(gorequests.WithCoalescing("X-Tenant"))
+
+		wg := sync.WaitGroup{}
+		texts := make([]string, 20)
+		for i := range texts {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				r := fac.New(http.MethodGet, server.URL+"/get").WithHeader("X-Tenant", "a")
+				text, err := r.Text()
+				as.Nil(err)
+				texts[i] = text
+				r.MustResponse().Header.Set("X-Hit", "changed by caller")
+			}(i)
+		}
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		as.Equal(int32(1), atomic.LoadInt32(&hits))
+		for _, v := range texts {
+			as.Equal("a1", v)
+		}
+	})
+
+	t.Run("selected header", func(t *testing.T) {
+		var hits int32
+		release := make(chan struct{})
+		server := newSlowServer(&hits, release)
+		defer server.Close()
+		fac := gorequests.NewFactory(gorequests.WithCoalescing("X-Tenant"))
+
+		wg := sync.WaitGroup{}
+		for _, tenant := range []string{"a", "b", "a", "b"} {
+			wg.Add(1)
+			go func(tenant string) {
+				defer wg.Done()
+				_, err := fac.New(http.MethodGet, server.URL+"/get").WithHeader("X-Tenant", tenant).Text()
+				as.Nil(err)
+			}(tenant)
+		}
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		as.Equal(int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		var hits int32
+		release := make(chan struct{})
+		server := newSlowServer(&hits, release)
+		defer server.Close()
+		fac := gorequests.NewFactory(gorequests.WithCoalescing(), gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+		wg := sync.WaitGroup{}
+		texts := map[string]string{}
+		lock := sync.Mutex{}
+		for _, token := range []string{"alice", "bob"} {
+			wg.Add(1)
+			go func(token string) {
+				defer wg.Done()
+				r := fac.New(http.MethodGet, server.URL+"/get").WithBearerToken(token)
+				text, err := r.Text()
+				as.Nil(err)
+				lock.Lock()
+				texts[token] = text
+				lock.Unlock()
+			}(token)
+		}
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		as.Equal(int32(2), atomic.LoadInt32(&hits))
+		as.NotEqual(texts["alice"], texts["bob"])
+	})
+
+	t.Run("cancel one caller", func(t *testing.T) {
+		var hits int32
+		release := make(chan struct{})
+		server := newSlowServer(&hits, release)
+		defer server.Close()
+		fac := gorequests.NewFactory(gorequests.WithCoalescing())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		canceled := make(chan error)
+		go func() {
+			_, err := fac.New(http.MethodGet, server.URL+"/get").WithContext(ctx).Text()
+			canceled <- err
+		}()
+		done := make(chan string)
+		go func() {
+			text, err := fac.New(http.MethodGet, server.URL+"/get").Text()
+			as.Nil(err)
+			done <- text
+		}()
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+		err := <-canceled
+		as.True(errors.Is(err, gorequests.ErrCanceled), err)
+
+		close(release)
+		as.Equal("1", <-done)
+		as.Equal(int32(1), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("join after all callers canceled", func(t *testing.T) {
+		var hits int32
+		release := make(chan struct{})
+		server := newSlowServer(&hits, release)
+		defer server.Close()
+		fac := gorequests.NewFactory(gorequests.WithCoalescing())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		canceled := make(chan error)
+		go func() {
+			_, err := fac.New(http.MethodGet, server.URL+"/get").WithContext(ctx).Text()
+			canceled <- err
+		}()
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+		as.True(errors.Is(<-canceled, gorequests.ErrCanceled))
+
+		// canceled upstream call may not return yet, late caller should not join it
+		done := make(chan error)
+		go func() {
+			_, err := fac.New(http.MethodGet, server.URL+"/get").Text()
+			done <- err
+		}()
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		as.Nil(<-done)
+		as.Equal(int32(2), atomic.LoadInt32(&hits))
+	})
+}
@@ -0,0 +1,571 @@
+package gorequests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCurlRedactHeaders is redacted by WithCurlRedact if no header is specified
+var defaultCurlRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+// CurlOption config ToCurl
+type CurlOption func(c *curlConfig)
+
+type curlConfig struct {
+	redactHeaders []string
+	redactQuery   []string
+}
+
+// WithCurlRedact replace value of headers with REDACTED, Authorization, Proxy-Authorization, Cookie
+// and X-Api-Key are redacted if headers is empty
+func WithCurlRedact(headers ...string) CurlOption {
+	return func(c *curlConfig) {
+		if len(headers) == 0 {
+			headers = defaultCurlRedactHeaders
+		}
+		for _, v := range headers {
+			c.redactHeaders = append(c.redactHeaders, http.CanonicalHeaderKey(v))
+		}
+	}
+}
+
+// WithCurlRedactQuery replace value of query params with REDACTED
+func WithCurlRedactQuery(params ...string) CurlOption {
+	return func(c *curlConfig) {
+		c.redactQuery = append(c.redactQuery, params...)
+	}
+}
+
+// ToCurl return curl command of request, it does not send request, and should be called before request is sent.
+//
+// body is written as --data-raw, multipart text part is written as --form-string,
+// and file part is written as -F 'key=@filename', which should exist when run the command.
+//
+// credentials applied when request is sent are not in the command, they are set by WithBearerTokenSource,
+// WithDigestAuth, WithOAuth2, WithAWSSigV4 and WithSigner, add them with -H when run the command.
+func (r *Request) ToCurl(options ...CurlOption) (string, error) {
+	config := new(curlConfig)
+	for _, v := range options {
+		v(config)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.err != nil {
+		return "", r.err
+	}
+	rawURL, err := r.buildRequestURL()
+	if err != nil {
+		return "", r.newError(KindInvalidRequest, "build url", err)
+	}
+	if len(config.redactQuery) > 0 {
+		if u, err := url.Parse(rawURL); err == nil {
+			query := u.Query()
+			for _, name := range config.redactQuery {
+				if _, ok := query[name]; ok {
+					query.Set(name, harRedacted)
+				}
+			}
+			u.RawQuery = query.Encode()
+			rawURL = u.String()
+		}
+	}
+	body, err := r.peekBody()
+	if err != nil {
+		return "", r.newError(KindInvalidRequest, "read body", err)
+	}
+
+	args := []string{"curl"}
+	switch {
+	case r.method == http.MethodHead:
+		args = append(args, "-I")
+	case r.method != http.MethodGet || len(body) > 0:
+		args = append(args, "-X", r.method)
+	}
+	args = append(args, shellQuote(rawURL))
+
+	redacted := map[string]bool{}
+	for _, v := range config.redactHeaders {
+		redacted[v] = true
+	}
+	header := r.header.Clone()
+	if r.persistentJar != nil {
+		if u, err := url.Parse(rawURL); err == nil {
+			for _, c := range r.persistentJar.Cookies(u) {
+				header.Add("Cookie", c.Name+"="+c.Value)
+			}
+		}
+	}
+
+	var parts [][2]string
+	if mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil && mediaType == "multipart/form-data" {
+		if parts, err = curlFormParts(body, params["boundary"]); err == nil {
+			header.Del("Content-Type") // curl set it with its own boundary
+		}
+	}
+
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			if redacted[k] {
+				v = harRedacted
+			}
+			if k == "Cookie" {
+				args = append(args, "-b", shellQuote(v))
+				continue
+			}
+			args = append(args, "-H", shellQuote(k+": "+v))
+		}
+	}
+
+	if parts != nil {
+		for _, v := range parts {
+			args = append(args, v[0], shellQuote(v[1]))
+		}
+	} else if len(body) > 0 {
+		args = append(args, "--data-raw", shellQuote(string(body)))
+	}
+
+	if r.timeout > 0 {
+		args = append(args, "--max-time", strconv.FormatFloat(r.timeout.Seconds(), 'f', -1, 64))
+	}
+	if r.isIgnoreSSL {
+		args = append(args, "-k")
+	}
+	if !r.isNoRedirect {
+		args = append(args, "-L")
+	}
+	return strings.Join(args, " "), nil
+}
+
+// peekBody read body without consuming it, body is replaced with a new reader of read bytes
+func (r *Request) peekBody() ([]byte, error) {
+	if r.rawBody != nil || r.body == nil {
+		return r.rawBody, nil
+	}
+	if buf, ok := r.body.(*bytes.Buffer); ok {
+		return buf.Bytes(), nil
+	}
+	bs, err := ioutil.ReadAll(r.body)
+	if err != nil {
+		return nil, err
+	}
+	r.body = bytes.NewReader(bs)
+	return bs, nil
+}
+
+// curlFormParts return flag and value of multipart parts, file part is -F and text part is --form-string
+func curlFormParts(body []byte, boundary string) ([][2]string, error) {
+	parts := [][2]string{}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FileName() != "" {
+			parts = append(parts, [2]string{"-F", part.FormName() + "=@" + part.FileName()})
+			continue
+		}
+		value, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, [2]string{"--form-string", part.FormName() + "=" + string(value)})
+	}
+}
+
+// shellQuote quote s with single quote for posix shell
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_./:@%+=,", c))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// FromCurl parse curl command to request, supported flags:
+//
+//	-X, --request          method
+//	-H, --header           header
+//	-d, --data, --data-ascii, --data-binary, --data-raw   body, @file read body from file (except --data-raw)
+//	--data-urlencode       url encoded body: content, =content, name=content, @file, name@file
+//	-F, --form             multipart field: name=value, name=@file, name=<file
+//	--form-string          multipart field: name=value, value is literal
+//	-G, --get              append data to query, and send GET request
+//	-u, --user             basic auth user:password
+//	-b, --cookie           cookie string
+//	-A, --user-agent       User-Agent header
+//	-e, --referer          Referer header
+//	-I, --head             HEAD request
+//	-k, --insecure         ignore ssl verify
+//	-L, --location         follow redirect, redirect is not followed without it, like curl
+//	-m, --max-time         timeout in seconds
+//	--url                  url
+//
+// short flags can be combined like -sSL, and value can be attached like -XPOST,
+// output only flags like -s, -v, -i, --compressed are ignored, other flags return error of KindInvalidRequest.
+func FromCurl(cmd string) (*Request, error) {
+	r, err := parseCurl(cmd)
+	if err != nil {
+		return nil, &Error{Kind: KindInvalidRequest, Op: "parse curl", Err: err}
+	}
+	return r, nil
+}
+
+func parseCurl(cmd string) (*Request, error) {
+	args, err := splitShellArgs(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, errors.New("command should start with curl")
+	}
+
+	var (
+		method, rawURL, user, cookie string
+		header                       = http.Header{}
+		data                         []string
+		forms                        []curlFormField
+		isGet, isHead, isInsecure    bool
+		isLocation                   bool
+		timeout                      time.Duration
+	)
+	args = args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		// -XPOST, -sSL
+		if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' {
+			if expanded, ok := splitCurlShortFlags(arg); ok {
+				args = append(args[:i], append(expanded, args[i+1:]...)...)
+				arg = args[i]
+			}
+		}
+		// --flag=value
+		if strings.HasPrefix(arg, "--") && strings.Contains(arg, "=") {
+			idx := strings.Index(arg, "=")
+			args = append(args[:i+1], append([]string{arg[idx+1:]}, args[i+1:]...)...)
+			arg = arg[:idx]
+		}
+		value := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("flag %s need value", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var v string
+		switch arg {
+		case "-X", "--request", "-H", "--header", "-d", "--data", "--data-ascii", "--data-binary", "--data-raw",
+			"--data-urlencode", "-F", "--form", "--form-string", "-u", "--user", "-b", "--cookie", "-A", "--user-agent",
+			"-e", "--referer", "-m", "--max-time", "--url":
+			if v, err = value(); err != nil {
+				return nil, err
+			}
+		}
+
+		switch arg {
+		case "-X", "--request":
+			method = strings.ToUpper(v)
+		case "-H", "--header":
+			idx := strings.Index(v, ":")
+			if idx < 0 {
+				return nil, fmt.Errorf("invalid header %q", v)
+			}
+			header.Add(strings.TrimSpace(v[:idx]), strings.TrimSpace(v[idx+1:]))
+		case "-d", "--data", "--data-ascii", "--data-binary":
+			if strings.HasPrefix(v, "@") {
+				bs, err := ioutil.ReadFile(v[1:])
+				if err != nil {
+					return nil, err
+				}
+				v = string(bs)
+				if arg != "--data-binary" {
+					v = strings.NewReplacer("\r", "", "\n", "").Replace(v)
+				}
+			}
+			data = append(data, v)
+		case "--data-raw":
+			data = append(data, v)
+		case "--data-urlencode":
+			encoded, err := curlDataURLEncode(v)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, encoded)
+		case "-F", "--form", "--form-string":
+			idx := strings.Index(v, "=")
+			if idx < 0 {
+				return nil, fmt.Errorf("invalid form %q", v)
+			}
+			forms = append(forms, curlFormField{name: v[:idx], value: v[idx+1:], isString: arg == "--form-string"})
+		case "-G", "--get":
+			isGet = true
+		case "-u", "--user":
+			user = v
+		case "-b", "--cookie":
+			cookie = v
+		case "-A", "--user-agent":
+			header.Set("User-Agent", v)
+		case "-e", "--referer":
+			header.Set("Referer", v)
+		case "-I", "--head":
+			isHead = true
+		case "-k", "--insecure":
+			isInsecure = true
+		case "-L", "--location":
+			isLocation = true
+		case "-m", "--max-time":
+			seconds, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max-time %q", v)
+			}
+			timeout = time.Duration(seconds * float64(time.Second))
+		case "--url":
+			rawURL = v
+		case "-s", "--silent", "-S", "--show-error", "-v", "--verbose", "-i", "--include", "--compressed",
+			"-f", "--fail", "--http1.1", "--http2", "-#", "--progress-bar":
+		default:
+			if strings.HasPrefix(arg, "-") && arg != "-" {
+				return nil, fmt.Errorf("unsupported flag %s", arg)
+			}
+			rawURL = arg
+		}
+	}
+	if rawURL == "" {
+		return nil, errors.New("no url")
+	}
+
+	switch {
+	case method != "":
+	case isHead:
+		method = http.MethodHead
+	case isGet:
+		method = http.MethodGet
+	case len(data) > 0 || len(forms) > 0:
+		method = http.MethodPost
+	default:
+		method = http.MethodGet
+	}
+	if isGet && len(data) > 0 {
+		sep := "?"
+		if strings.Contains(rawURL, "?") {
+			sep = "&"
+		}
+		rawURL += sep + strings.Join(data, "&")
+		data = nil
+	}
+
+	r := New(method, rawURL).WithIgnoreSSL(isInsecure).WithRedirect(isLocation)
+	if timeout > 0 {
+		r.WithTimeout(timeout)
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			r.WithHeader(k, v)
+		}
+	}
+	if user != "" {
+		r.WithHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user)))
+	}
+	if cookie != "" {
+		r.WithHeader("Cookie", cookie)
+	}
+	switch {
+	case len(forms) > 0:
+		contentType, body, err := curlMultipartBody(forms)
+		if err != nil {
+			return nil, err
+		}
+		r.WithBody(body).WithHeader("Content-Type", contentType)
+	case len(data) > 0:
+		r.WithBody(strings.Join(data, "&"))
+		if header.Get("Content-Type") == "" {
+			r.WithHeader("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	return r, nil
+}
+
+// short flags of FromCurl, value of flag may be attached like -XPOST
+const (
+	curlShortValueFlags = "XHdFubAem"
+	curlShortBoolFlags  = "GIkLsSvif#"
+)
+
+// splitCurlShortFlags split combined short flags like -sSL and attached value like -XPOST into separate args,
+// return false if there is unknown flag
+func splitCurlShortFlags(arg string) ([]string, bool) {
+	var res []string
+	for i := 1; i < len(arg); i++ {
+		switch c := arg[i]; {
+		case strings.IndexByte(curlShortValueFlags, c) >= 0:
+			res = append(res, "-"+string(c))
+			if i+1 < len(arg) {
+				res = append(res, arg[i+1:])
+			}
+			return res, true
+		case strings.IndexByte(curlShortBoolFlags, c) >= 0:
+			res = append(res, "-"+string(c))
+		default:
+			return nil, false
+		}
+	}
+	return res, true
+}
+
+func curlDataURLEncode(v string) (string, error) {
+	readFile := func(name string) (string, error) {
+		bs, err := ioutil.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	}
+	if idx := strings.IndexAny(v, "=@"); idx >= 0 {
+		name, content := v[:idx], v[idx+1:]
+		if v[idx] == '@' {
+			var err error
+			if content, err = readFile(content); err != nil {
+				return "", err
+			}
+		}
+		if name == "" {
+			return url.QueryEscape(content), nil
+		}
+		return name + "=" + url.QueryEscape(content), nil
+	}
+	return url.QueryEscape(v), nil
+}
+
+// curlFormField is field of -F, --form and --form-string, value of --form-string is not file even if it starts with @ or <
+type curlFormField struct {
+	name     string
+	value    string
+	isString bool
+}
+
+func curlMultipartBody(forms []curlFormField) (string, []byte, error) {
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+	for _, field := range forms {
+		name, value := field.name, field.value
+		switch {
+		case field.isString:
+			if err := w.WriteField(name, value); err != nil {
+				return "", nil, err
+			}
+		case strings.HasPrefix(value, "@"):
+			filename := strings.SplitN(value[1:], ";", 2)[0]
+			bs, err := ioutil.ReadFile(filename)
+			if err != nil {
+				return "", nil, err
+			}
+			part, err := w.CreateFormFile(name, filepath.Base(filename))
+			if err != nil {
+				return "", nil, err
+			}
+			if _, err = part.Write(bs); err != nil {
+				return "", nil, err
+			}
+		case strings.HasPrefix(value, "<"):
+			bs, err := ioutil.ReadFile(strings.SplitN(value[1:], ";", 2)[0])
+			if err != nil {
+				return "", nil, err
+			}
+			if err = w.WriteField(name, string(bs)); err != nil {
+				return "", nil, err
+			}
+		default:
+			if err := w.WriteField(name, value); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", nil, err
+	}
+	return w.FormDataContentType(), buf.Bytes(), nil
+}
+
+// splitShellArgs split command to args like posix shell, support single quote, double quote,
+// backslash escape and line continuation
+func splitShellArgs(cmd string) ([]string, error) {
+	var (
+		args    []string
+		buf     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, c := range cmd {
+		switch {
+		case escaped:
+			escaped = false
+			if c == '\n' {
+				continue
+			}
+			if quote == '"' && !strings.ContainsRune("$`\"\\", c) {
+				buf.WriteRune('\\')
+			}
+			buf.WriteRune(c)
+			inArg = true
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+				continue
+			}
+			buf.WriteRune(c)
+		case c == '\\':
+			escaped = true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+				continue
+			}
+			buf.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, buf.String())
+				buf.Reset()
+				inArg = false
+			}
+		default:
+			buf.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, buf.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,182 @@
+package gorequests_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ToCurl(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("json", func(t *testing.T) {
+		cmd, err := gorequests.New(http.MethodPost, "https://example.com/users/{id}").
+			WithPathParam("id", "1").
+			WithQuery("token", "t").
+			WithHeader("Authorization", "Bearer secret").
+			WithJSON(map[string]string{"name": "it's"}).
+			WithTimeout(1500*time.Millisecond).
+			WithIgnoreSSL(true).
+			ToCurl(gorequests.WithCurlRedact(), gorequests.WithCurlRedactQuery("token"))
+		as.Nil(err)
+		as.Equal(`curl -X POST 'https://example.com/users/1?token=REDACTED' -H 'Authorization: REDACTED' -H 'Content-Type: application/json' --data-raw '{"name":"it'\''s"}' --max-time 1.5 -k -L`, cmd)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		cmd, err := gorequests.New(http.MethodGet, "https://example.com/get").WithRedirect(false).WithHeader("Cookie", "a=b").ToCurl()
+		as.Nil(err)
+		as.Equal(`curl https://example.com/get -b a=b`, cmd)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		r := gorequests.New(http.MethodPost, joinHttpBinURL("/post")).WithFile("1.txt", strings.NewReader("hi"), "file", map[string]string{"field": "val"})
+		cmd, err := r.ToCurl()
+		as.Nil(err)
+		as.Equal(`curl -X POST `+joinHttpBinURL("/post")+` -F file=@1.txt --form-string field=val -L`, cmd)
+
+		// body is still readable after ToCurl
+		resp := struct {
+			Files map[string]string `json:"files"`
+		}{}
+		as.Nil(r.Unmarshal(&resp))
+		as.Equal("hi", resp.Files["file"])
+	})
+
+	t.Run("values are not files", func(t *testing.T) {
+		cmd, err := gorequests.New(http.MethodPost, "https://example.com/post").WithBody("@/etc/passwd").ToCurl()
+		as.Nil(err)
+		as.Equal(`curl -X POST https://example.com/post --data-raw @/etc/passwd -L`, cmd)
+
+		cmd, err = gorequests.New(http.MethodPost, "https://example.com/post").
+			WithFile("1.txt", strings.NewReader("hi"), "file", map[string]string{"a": "@/etc/passwd", "b": "</etc/passwd"}).ToCurl()
+		as.Nil(err)
+		as.Contains(cmd, `--form-string a=@/etc/passwd`)
+		as.Contains(cmd, `--form-string 'b=</etc/passwd'`)
+
+		fromCurl, err := gorequests.FromCurl(`curl ` + joinHttpBinURL("/post") + ` --form-string a=@/etc/passwd --form-string 'b=</etc/passwd'`)
+		as.Nil(err)
+		resp := struct {
+			Form map[string]string `json:"form"`
+		}{}
+		as.Nil(fromCurl.WithLogger(gorequests.NewDiscardLogger()).Unmarshal(&resp))
+		as.Equal(map[string]string{"a": "@/etc/passwd", "b": "</etc/passwd"}, resp.Form)
+	})
+}
+
+func Test_FromCurl(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("json", func(t *testing.T) {
+		r, err := gorequests.FromCurl(`curl -X PUT '` + joinHttpBinURL("/anything?a=1") + `' \
+  -H 'Content-Type: application/json' -H "X-Name: \"bob\"" \
+  -d '{"id": 1}' -u user:pass -b 'c=d' -k -s --max-time=3`)
+		as.Nil(err)
+		resp := struct {
+			Method  string            `json:"method"`
+			Args    map[string]string `json:"args"`
+			Headers map[string]string `json:"headers"`
+			JSON    map[string]int    `json:"json"`
+		}{}
+		as.Nil(r.Unmarshal(&resp))
+		as.Equal(http.MethodPut, resp.Method)
+		as.Equal("1", resp.Args["a"])
+		as.Equal(`"bob"`, resp.Headers["X-Name"])
+		as.Equal("Basic dXNlcjpwYXNz", resp.Headers["Authorization"])
+		as.Equal("c=d", resp.Headers["Cookie"])
+		as.Equal(1, resp.JSON["id"])
+	})
+
+	t.Run("form", func(t *testing.T) {
+		file := t.TempDir() + "/a.txt"
+		as.Nil(ioutil.WriteFile(file, []byte("content"), 0o644))
+
+		r, err := gorequests.FromCurl(`curl ` + joinHttpBinURL("/post") + ` -F name=bob -F file=@` + file)
+		as.Nil(err)
+		resp := struct {
+			Form  map[string]string `json:"form"`
+			Files map[string]string `json:"files"`
+		}{}
+		as.Nil(r.Unmarshal(&resp))
+		as.Equal("bob", resp.Form["name"])
+		as.Equal("content", resp.Files["file"])
+	})
+
+	t.Run("data-urlencode and get", func(t *testing.T) {
+		r, err := gorequests.FromCurl(`curl -G ` + joinHttpBinURL("/get") + ` --data-urlencode 'q=a b&c' -d x=1`)
+		as.Nil(err)
+		resp := struct {
+			Args map[string]string `json:"args"`
+		}{}
+		as.Nil(r.Unmarshal(&resp))
+		as.Equal(map[string]string{"q": "a b&c", "x": "1"}, resp.Args)
+	})
+
+	t.Run("attached short flags", func(t *testing.T) {
+		r, err := gorequests.FromCurl(`curl -XPATCH -HAccept:x '-HX-Name: bob' -HContent-Type:text/plain -dfoo -ubob:pass -sSL ` + joinHttpBinURL("/anything"))
+		as.Nil(err)
+		resp := struct {
+			Method  string            `json:"method"`
+			Headers map[string]string `json:"headers"`
+			Data    string            `json:"data"`
+		}{}
+		as.Nil(r.Unmarshal(&resp))
+		as.Equal(http.MethodPatch, resp.Method)
+		as.Equal("x", resp.Headers["Accept"])
+		as.Equal("bob", resp.Headers["X-Name"])
+		as.Equal("Basic Ym9iOnBhc3M=", resp.Headers["Authorization"])
+		as.Equal("foo", resp.Data)
+
+		r, err = gorequests.FromCurl(`curl -sIm3 ` + joinHttpBinURL("/get"))
+		as.Nil(err)
+		as.Equal(http.MethodHead, r.Method())
+
+		_, err = gorequests.FromCurl(`curl -sx http://p https://example.com`)
+		as.Equal(gorequests.KindInvalidRequest, gorequests.KindOf(err))
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		r, err := gorequests.FromCurl(`curl ` + joinHttpBinURL("/redirect/1"))
+		as.Nil(err)
+		as.Equal(http.StatusFound, r.MustResponseStatus())
+
+		r, err = gorequests.FromCurl(`curl -L ` + joinHttpBinURL("/redirect/1"))
+		as.Nil(err)
+		as.Equal(http.StatusOK, r.MustResponseStatus())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, cmd := range []string{
+			`wget https://example.com`,
+			`curl --proxy http://p https://example.com`,
+			`curl 'https://example.com`,
+			`curl -H https://example.com`,
+			`curl -d @/not/exist https://example.com`,
+		} {
+			_, err := gorequests.FromCurl(cmd)
+			as.Equal(gorequests.KindInvalidRequest, gorequests.KindOf(err), cmd)
+			as.True(errors.Is(err, gorequests.ErrInvalidRequest), cmd)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		cmd, err := gorequests.New(http.MethodPatch, joinHttpBinURL("/anything")).WithHeader("X-A", "1").WithHeader("Content-Type", "text/plain").WithBody("raw body").ToCurl()
+		as.Nil(err)
+		r, err := gorequests.FromCurl(cmd)
+		as.Nil(err)
+		resp := struct {
+			Method  string            `json:"method"`
+			Data    string            `json:"data"`
+			Headers map[string]string `json:"headers"`
+		}{}
+		as.Nil(r.Unmarshal(&resp))
+		as.Equal(http.MethodPatch, resp.Method)
+		as.Equal("raw body", resp.Data)
+		as.Equal("1", resp.Headers["X-A"])
+	})
+}
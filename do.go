@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -20,9 +21,18 @@ func (r *Request) doInternalRequest() error {
 		return nil
 	}
 
-	r.cachedurl = r.parseRequestURL()
+	cachedurl, err := r.buildRequestURL()
+	if err != nil {
+		return r.newError(KindInvalidRequest, "build url", err)
+	}
+	r.cachedurl = cachedurl
+	if r.signer != nil {
+		if err := r.sign(); err != nil {
+			return err
+		}
+	}
 
-	r.logger.Info(r.Context(), "[gorequests] %s: %s, body=%s, header=%+v", r.method, r.cachedurl, r.rawBody, r.header)
+	r.logger.Info(r.Context(), "[gorequests] %s: %s, body=%s, header=%+v", r.method, r.cachedurl, r.rawBody, redactLogHeader(r.header))
 
 	if r.persistentJar != nil {
 		defer func() {
@@ -32,21 +42,20 @@ func (r *Request) doInternalRequest() error {
 		}()
 	}
 
-	req, err := http.NewRequest(r.method, r.cachedurl, r.body)
+	req, err := http.NewRequestWithContext(r.Context(), r.method, r.cachedurl, r.body)
 	if err != nil {
-		return fmt.Errorf("[gorequest] %s %s new request failed: %w", r.method, r.cachedurl, err)
+		return r.newError(KindInvalidRequest, "new request", err)
 	}
 
 	req.Header = r.header
+	if r.cache != nil {
+		req = req.WithContext(withCacheStatus(req.Context(), &r.cacheStatus))
+	}
 
 	// TODO: reuse client
 	c := &http.Client{
-		Timeout: r.timeout,
-	}
-	if r.isIgnoreSSL {
-		c.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
+		Timeout:   r.timeout,
+		Transport: r.roundTripper(),
 	}
 	if r.persistentJar != nil {
 		c.Jar = r.persistentJar
@@ -66,7 +75,7 @@ func (r *Request) doInternalRequest() error {
 	r.resp = resp
 	r.isRequest = true
 	if err != nil {
-		return fmt.Errorf("[gorequest] %s %s send request failed: %w", r.method, r.cachedurl, err)
+		return r.newError(classifyError(err, KindTransport), "send request", err)
 	}
 	err = r.doProduceLog()
 	if err != nil {
@@ -90,7 +99,7 @@ func (r *Request) doRead() error {
 		r.bytes, err = ioutil.ReadAll(r.resp.Body)
 		r.isRead = true
 		if err != nil {
-			return fmt.Errorf("[gorequest] %s %s read response failed: %w", r.method, r.cachedurl, err)
+			return r.newError(classifyError(err, KindReadResponse), "read response", err)
 		}
 
 		r.logger.Info(r.Context(), "[gorequests] %s: %s, status_code: %d, header: %s, doRead: %s", r.method, r.cachedurl, r.resp.StatusCode, r.resp.Header, r.bytes)
@@ -111,11 +120,30 @@ func (r *Request) doProduceLog() error {
 		return err
 	}
 
+	message := r.newLogMessage()
+	r.log = &message
+	data, _ := json.Marshal(message)
+
+	err := r.logProducer.SendLogMessage(r.context, data)
+	r.isSend = true
+	if err != nil {
+		r.logger.Error(r.context, "[gorequest] SendLogMessage failed, err: %+v", err)
+		return r.newError(KindLogProducer, "send log message", fmt.Errorf("%w, message: %s", err, string(data)))
+	}
+	r.logger.Info(r.context, "[gorequests] SendLogMessage succeeded")
+
+	r.logger.Info(r.Context(), "[gorequests] %s: %s, produce log: %s", r.method, r.cachedurl, string(data))
+	return nil
+}
+
+// newLogMessage build log message of sent request
+func (r *Request) newLogMessage() LogMessage {
 	message := LogMessage{
 		Method:            r.method,
 		Url:               r.cachedurl,
+		UrlTemplate:       r.url,
 		RequestBody:       string(r.rawBody),
-		RequestHeader:     r.header,
+		RequestHeader:     redactLogHeader(r.header),
 		RequestTime:       r.reqTime.Format(time.RFC3339),
 		ResponseBody:      string(r.bytes),
 		ResponseHeader:    r.resp.Header,
@@ -123,24 +151,63 @@ func (r *Request) doProduceLog() error {
 		ResponseTime:      r.respTime.Format(time.RFC3339),
 		TimeConsuming:     (r.respTime.UnixNano() - r.reqTime.UnixNano()) / 1000000,
 		LogId:             r.logId,
+		CacheStatus:       string(r.cacheStatus),
 		RequestType:       RequestMessageTypeOut,
 	}
 	if r.doErr != nil {
 		message.ErrorMessage = r.doErr.Error()
 	}
-	r.log = &message
-	data, _ := json.Marshal(message)
+	return message
+}
 
-	err := r.logProducer.SendLogMessage(r.context, data)
-	r.isSend = true
-	if err != nil {
-		r.logger.Error(r.context, "[gorequest] SendLogMessage failed, err: %+v", err)
-		return fmt.Errorf("[gorequest] %s %s send log message failed %w, message: %s", r.method, r.cachedurl, err, string(data))
+// logRedactHeaders carry credentials, they are redacted in request log and LogMessage
+var logRedactHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+// redactLogHeader return copy of header with credentials replaced by REDACTED
+func redactLogHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, k := range logRedactHeaders {
+		if _, ok := header[k]; ok {
+			header[k] = []string{harRedacted}
+		}
 	}
-	r.logger.Info(r.context, "[gorequests] SendLogMessage succeeded")
+	return header
+}
 
-	r.logger.Info(r.Context(), "[gorequests] %s: %s, produce log: %s", r.method, r.cachedurl, string(data))
-	return nil
+// roundTripper build transport chain of request, from outer to inner: auth, har recorder, cache, coalescer, base transport,
+// base transport of *http.Transport is cloned to skip tls verify if WithIgnoreSSL is set, other transports are kept as is
+func (r *Request) roundTripper() http.RoundTripper {
+	var rt http.RoundTripper = http.DefaultTransport
+	if r.transport != nil {
+		rt = r.transport
+	} else if v := getDefaultTransport(); v != nil {
+		rt = v
+	}
+	if base, ok := rt.(*http.Transport); ok && r.isIgnoreSSL {
+		base = base.Clone()
+		if base.TLSClientConfig == nil {
+			base.TLSClientConfig = &tls.Config{}
+		}
+		base.TLSClientConfig.InsecureSkipVerify = true
+		rt = base
+	}
+	if r.coalescer != nil {
+		rt = &coalesceTransport{coalescer: r.coalescer, next: rt}
+	}
+	if r.cache != nil {
+		rt = &cacheTransport{cache: r.cache, next: rt}
+	}
+	if r.harRecorder != nil {
+		rt = &harTransport{recorder: r.harRecorder, next: rt}
+	}
+	if r.auth != nil {
+		host := ""
+		if u, err := url.Parse(r.cachedurl); err == nil {
+			host = u.Host
+		}
+		rt = &authTransport{auth: r.auth, host: host, next: rt}
+	}
+	return rt
 }
 
 func (r *Request) doRequestFactor(f func() error) error {
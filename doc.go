@@ -22,5 +22,11 @@ request with context
 request with no redirect
     gorequests.New(http.MethodGet, "https://httpbin.org/status/302).WithRedirect(false)
 
+for decode json response with generics
+    user, err := gorequests.JSON[User](gorequests.New(http.MethodGet, "https://httpbin.org/get"))
+
+request with path params
+    gorequests.New(http.MethodGet, "https://httpbin.org/anything/{id}").WithPathParam("id", "a/b")
+
 */
 package gorequests
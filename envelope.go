@@ -0,0 +1,107 @@
+package gorequests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Envelope describe the {"code": 0, "msg": "ok", "data": {...}} wrapper of api response,
+// Unmarshal decode data into val when code is success, or return *EnvelopeError, see WithEnvelope.
+//
+// zero value of Envelope is ready to use.
+type Envelope struct {
+	CodeField    string                 // field of code, default is "code"
+	MessageField string                 // field of message, default is "msg"
+	DataPath     string                 // dot separated path of data, default is "data"
+	LogIDField   string                 // field of log id, optional
+	LogIDHeader  string                 // response header of log id, optional
+	IsSuccess    func(code string) bool // code is json number or unquoted json string, default is code == "0"
+}
+
+// EnvelopeError is returned when code of Envelope is not success
+type EnvelopeError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Code       string
+	Message    string
+	LogID      string // log id of response, or log id of request if not found, see WithLogId
+}
+
+func (e *EnvelopeError) Error() string {
+	return fmt.Sprintf("gorequests: %s %s business error, code: %s, msg: %s, log_id: %s", e.Method, e.URL, e.Code, e.Message, e.LogID)
+}
+
+// Is match ErrBusiness
+func (e *EnvelopeError) Is(target error) bool {
+	return target == ErrBusiness
+}
+
+func (e *Envelope) unwrap(r *Request, bs []byte) ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(bs, &fields); err != nil {
+		return nil, r.newError(KindDecode, "unmarshal envelope", decodeError(err, bs))
+	}
+
+	rawCode, ok := fields[defaultString(e.CodeField, "code")]
+	if !ok {
+		return nil, r.newError(KindDecode, "unmarshal envelope", decodeError(fmt.Errorf("field %s not found", defaultString(e.CodeField, "code")), bs))
+	}
+	code := rawJSONString(rawCode)
+	isSuccess := e.IsSuccess
+	if isSuccess == nil {
+		isSuccess = func(code string) bool { return code == "0" }
+	}
+	if !isSuccess(code) {
+		logID := r.logId
+		if e.LogIDHeader != "" && r.resp.Header.Get(e.LogIDHeader) != "" {
+			logID = r.resp.Header.Get(e.LogIDHeader)
+		}
+		if v, ok := fields[e.LogIDField]; ok && e.LogIDField != "" {
+			logID = rawJSONString(v)
+		}
+		return nil, &EnvelopeError{
+			Method:     r.method,
+			URL:        r.cachedurl,
+			StatusCode: r.resp.StatusCode,
+			Code:       code,
+			Message:    rawJSONString(fields[defaultString(e.MessageField, "msg")]),
+			LogID:      logID,
+		}
+	}
+
+	data := json.RawMessage("null")
+	for i, key := range strings.Split(defaultString(e.DataPath, "data"), ".") {
+		if i > 0 {
+			fields = map[string]json.RawMessage{}
+			if err := json.Unmarshal(data, &fields); err != nil {
+				return nil, r.newError(KindDecode, "unmarshal envelope data", decodeError(err, data))
+			}
+		}
+		if data, ok = fields[key]; !ok {
+			return []byte("null"), nil
+		}
+	}
+	return data, nil
+}
+
+// rawJSONString return unquoted string for json string, raw json for others
+func rawJSONString(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '"' {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			return s
+		}
+	}
+	return string(raw)
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package gorequests_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Envelope(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Log-Id", "header-log-id")
+		switch r.URL.Path {
+		case "/ok":
+			_, _ = w.Write([]byte(`{"code":0,"msg":"ok","data":{"id":1,"name":"a"}}`))
+		case "/fail":
+			_, _ = w.Write([]byte(`{"code":10001,"msg":"no permission","log_id":"body-log-id"}`))
+		case "/custom":
+			_, _ = w.Write([]byte(`{"status":"OK","message":"","result":{"user":{"id":2}}}`))
+		case "/custom-fail":
+			_, _ = w.Write([]byte(`{"status":"DENIED","message":"denied"}`))
+		case "/no-code":
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Run("default", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithEnvelope(&gorequests.Envelope{}), gorequests.WithBaseURL(server.URL))
+
+		user := testUser{}
+		as.Nil(fac.New(http.MethodGet, "/ok").Unmarshal(&user))
+		as.Equal(testUser{ID: 1, Name: "a"}, user)
+
+		err := fac.New(http.MethodGet, "/fail").WithLogId("request-log-id").Unmarshal(&user)
+		as.True(errors.Is(err, gorequests.ErrBusiness))
+		as.Equal(gorequests.KindBusiness, gorequests.KindOf(err))
+		var envelopeErr *gorequests.EnvelopeError
+		as.True(errors.As(err, &envelopeErr))
+		as.Equal("10001", envelopeErr.Code)
+		as.Equal("no permission", envelopeErr.Message)
+		as.Equal("request-log-id", envelopeErr.LogID)
+		as.Equal(200, envelopeErr.StatusCode)
+
+		err = fac.New(http.MethodGet, "/no-code").Unmarshal(&user)
+		as.True(errors.Is(err, gorequests.ErrDecode))
+
+		text, err := fac.New(http.MethodGet, "/fail").Text()
+		as.Nil(err)
+		as.Contains(text, "no permission")
+	})
+
+	t.Run("log id", func(t *testing.T) {
+		err := gorequests.New(http.MethodGet, server.URL+"/fail").WithEnvelope(&gorequests.Envelope{LogIDHeader: "X-Log-Id"}).Unmarshal(&testUser{})
+		var envelopeErr *gorequests.EnvelopeError
+		as.True(errors.As(err, &envelopeErr))
+		as.Equal("header-log-id", envelopeErr.LogID)
+
+		err = gorequests.New(http.MethodGet, server.URL+"/fail").WithEnvelope(&gorequests.Envelope{LogIDHeader: "X-Log-Id", LogIDField: "log_id"}).Unmarshal(&testUser{})
+		as.True(errors.As(err, &envelopeErr))
+		as.Equal("body-log-id", envelopeErr.LogID)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		envelope := &gorequests.Envelope{
+			CodeField:    "status",
+			MessageField: "message",
+			DataPath:     "result.user",
+			IsSuccess:    func(code string) bool { return code == "OK" },
+		}
+		s := gorequests.NewSession(t.TempDir()+"/cookie.json", gorequests.WithEnvelope(envelope))
+
+		user := testUser{}
+		as.Nil(s.New(http.MethodGet, server.URL+"/custom").Unmarshal(&user))
+		as.Equal(2, user.ID)
+
+		err := s.New(http.MethodGet, server.URL+"/custom-fail").Unmarshal(&user)
+		var envelopeErr *gorequests.EnvelopeError
+		as.True(errors.As(err, &envelopeErr))
+		as.Equal("DENIED", envelopeErr.Code)
+		as.Equal("denied", envelopeErr.Message)
+	})
+
+	t.Run("generic", func(t *testing.T) {
+		user, err := gorequests.JSON[testUser](gorequests.New(http.MethodGet, server.URL+"/ok").WithEnvelope(&gorequests.Envelope{}))
+		as.Nil(err)
+		as.Equal(1, user.ID)
+	})
+}
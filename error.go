@@ -0,0 +1,275 @@
+package gorequests
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// ErrorKind classify errors returned by gorequests, see KindOf
+type ErrorKind int
+
+const (
+	KindUnknown        ErrorKind = iota
+	KindInvalidRequest           // build request failed: invalid url, path param, query, body
+	KindAlreadySent              // set request params after request sent
+	KindTimeout                  // request timeout or context deadline exceeded
+	KindCanceled                 // context canceled
+	KindDNS                      // dns lookup failed
+	KindConnRefused              // connection refused
+	KindTLS                      // tls handshake or certificate verify failed
+	KindTransport                // other network error
+	KindReadResponse             // read response body failed
+	KindDecode                   // decode response body failed: gzip, json
+	KindStatus                   // unexpected response status, see HTTPError
+	KindLogProducer              // send log message failed
+	KindBusiness                 // api response code is not success, see EnvelopeError
+	KindSchema                   // response violate json schema, see SchemaError
+	KindAuth                     // get or apply credentials failed: token source, oauth2 refresh, request signing
+)
+
+var kindNames = map[ErrorKind]string{
+	KindUnknown:        "unknown",
+	KindInvalidRequest: "invalid_request",
+	KindAlreadySent:    "already_sent",
+	KindTimeout:        "timeout",
+	KindCanceled:       "canceled",
+	KindDNS:            "dns",
+	KindConnRefused:    "conn_refused",
+	KindTLS:            "tls",
+	KindTransport:      "transport",
+	KindReadResponse:   "read_response",
+	KindDecode:         "decode",
+	KindStatus:         "status",
+	KindLogProducer:    "log_producer",
+	KindBusiness:       "business",
+	KindSchema:         "schema",
+	KindAuth:           "auth",
+}
+
+func (k ErrorKind) String() string {
+	if v, ok := kindNames[k]; ok {
+		return v
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// sentinel errors, every *Error match the sentinel of its kind with errors.Is
+var (
+	ErrInvalidRequest   = errors.New("gorequests: invalid request")
+	ErrAlreadySent      = errors.New("gorequests: request already sent")
+	ErrTimeout          = errors.New("gorequests: timeout")
+	ErrCanceled         = errors.New("gorequests: canceled")
+	ErrDNS              = errors.New("gorequests: dns lookup failed")
+	ErrConnRefused      = errors.New("gorequests: connection refused")
+	ErrTLS              = errors.New("gorequests: tls failed")
+	ErrTransport        = errors.New("gorequests: transport failed")
+	ErrReadResponse     = errors.New("gorequests: read response failed")
+	ErrDecode           = errors.New("gorequests: decode response failed")
+	ErrUnexpectedStatus = errors.New("gorequests: unexpected status")
+	ErrLogProducer      = errors.New("gorequests: send log message failed")
+	ErrBusiness         = errors.New("gorequests: business error")
+	ErrSchemaViolation  = errors.New("gorequests: response schema violation")
+	ErrAuth             = errors.New("gorequests: authenticate failed")
+)
+
+// detail sentinel errors of KindInvalidRequest
+var (
+	ErrUnresolvedPathParam = errors.New("unresolved path param")
+	ErrInvalidPathParam    = errors.New("invalid path param")
+	ErrBaseURLEscape       = errors.New("escape base url")
+	ErrUnsupportedQuery    = errors.New("unsupported query type")
+)
+
+var kindErrors = map[ErrorKind]error{
+	KindInvalidRequest: ErrInvalidRequest,
+	KindAlreadySent:    ErrAlreadySent,
+	KindTimeout:        ErrTimeout,
+	KindCanceled:       ErrCanceled,
+	KindDNS:            ErrDNS,
+	KindConnRefused:    ErrConnRefused,
+	KindTLS:            ErrTLS,
+	KindTransport:      ErrTransport,
+	KindReadResponse:   ErrReadResponse,
+	KindDecode:         ErrDecode,
+	KindStatus:         ErrUnexpectedStatus,
+	KindLogProducer:    ErrLogProducer,
+	KindBusiness:       ErrBusiness,
+	KindSchema:         ErrSchemaViolation,
+	KindAuth:           ErrAuth,
+}
+
+// Error is returned by gorequests for every failure except unexpected status, business error and schema violation,
+// which are *HTTPError, *EnvelopeError and *SchemaError
+type Error struct {
+	Kind   ErrorKind
+	Op     string // failed operation, like: send request, read response
+	Method string
+	URL    string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Method == "" && e.URL == "" {
+		return fmt.Sprintf("gorequests: %s failed: %s", e.Op, e.Err)
+	}
+	return fmt.Sprintf("gorequests: %s %s %s failed: %s", e.Method, e.URL, e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is match the sentinel error of e.Kind
+func (e *Error) Is(target error) bool {
+	return target != nil && kindErrors[e.Kind] == target
+}
+
+// Timeout report whether the error is a timeout
+func (e *Error) Timeout() bool {
+	return e.Kind == KindTimeout
+}
+
+// Temporary report whether retry the request may succeed
+func (e *Error) Temporary() bool {
+	switch e.Kind {
+	case KindTimeout, KindConnRefused, KindTransport, KindReadResponse:
+		return true
+	case KindDNS:
+		var dnsErr *net.DNSError
+		return errors.As(e.Err, &dnsErr) && dnsErr.IsTemporary
+	}
+	return false
+}
+
+// KindOf return kind of gorequests error, KindUnknown for other error
+func KindOf(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return KindStatus
+	}
+	var envelopeErr *EnvelopeError
+	if errors.As(err, &envelopeErr) {
+		return KindBusiness
+	}
+	var schemaErr *SchemaError
+	if errors.As(err, &schemaErr) {
+		return KindSchema
+	}
+	return KindUnknown
+}
+
+func (r *Request) newError(kind ErrorKind, op string, err error) *Error {
+	u := r.cachedurl
+	if u == "" {
+		u = r.url
+	}
+	return &Error{Kind: kind, Op: op, Method: r.method, URL: u, Err: err}
+}
+
+// classifyError classify network error, return fallback if err is not recognized
+func classifyError(err error, fallback ErrorKind) ErrorKind {
+	var (
+		authErr       *authError
+		dnsErr        *net.DNSError
+		netErr        net.Error
+		recordHeadErr tls.RecordHeaderError
+		unknownAuth   x509.UnknownAuthorityError
+		hostnameErr   x509.HostnameError
+		certInvalid   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case errors.As(err, &authErr):
+		return KindAuth
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsTimeout {
+			return KindTimeout
+		}
+		return KindDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return KindConnRefused
+	case errors.As(err, &recordHeadErr), errors.As(err, &unknownAuth), errors.As(err, &hostnameErr), errors.As(err, &certInvalid):
+		return KindTLS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return KindTimeout
+	case errors.As(err, &netErr):
+		return KindTransport
+	}
+	return fallback
+}
+
+// decodeErrorBodyLimit max bytes of response body kept in decode error
+const decodeErrorBodyLimit = 256
+
+// decodeError wrap err with truncated body which failed to decode
+func decodeError(err error, body []byte) error {
+	if len(body) > decodeErrorBodyLimit {
+		return fmt.Errorf("%w, body: %s...", err, body[:decodeErrorBodyLimit])
+	}
+	return fmt.Errorf("%w, body: %s", err, body)
+}
+
+// httpErrorBodyLimit max bytes of response body kept in HTTPError
+const httpErrorBodyLimit = 2048
+
+// HTTPError is returned when response status is not expected, see WithExpectStatus
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte      // response body, truncated to 2 KiB
+	Result     interface{} // error result decoded from response body, see WithErrorResult
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("gorequests: %s %s unexpected status %d, body: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// Is match ErrUnexpectedStatus
+func (e *HTTPError) Is(target error) bool {
+	return target == ErrUnexpectedStatus
+}
+
+// Timeout report whether the status is 408 or 504
+func (e *HTTPError) Timeout() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusGatewayTimeout
+}
+
+// Temporary report whether retry the request may succeed
+func (e *HTTPError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func newHTTPError(r *Request, body []byte) *HTTPError {
+	if len(body) > httpErrorBodyLimit {
+		body = body[:httpErrorBodyLimit]
+	}
+	return &HTTPError{
+		Method:     r.method,
+		URL:        r.cachedurl,
+		StatusCode: r.resp.StatusCode,
+		Status:     r.resp.Status,
+		Header:     r.resp.Header.Clone(),
+		Body:       append([]byte(nil), body...),
+	}
+}
@@ -0,0 +1,274 @@
+package gorequests_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStatusServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/status/"))
+		w.Header().Set("X-Status", strconv.Itoa(code))
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(`{"status":` + strconv.Itoa(code) + `,"padding":"` + strings.Repeat("x", 4096) + `"}`))
+	}))
+}
+
+func Test_HTTPError(t *testing.T) {
+	as := assert.New(t)
+	server := newStatusServer()
+	defer server.Close()
+
+	t.Run("not check by default", func(t *testing.T) {
+		text, err := gorequests.New(http.MethodGet, server.URL+"/status/500").Text()
+		as.Nil(err)
+		as.Contains(text, `"status":500`)
+	})
+
+	t.Run("2xx", func(t *testing.T) {
+		resp := map[string]interface{}{}
+		as.Nil(gorequests.New(http.MethodGet, server.URL+"/status/201").WithExpectStatus().Unmarshal(&resp))
+		as.Equal(float64(201), resp["status"])
+
+		err := gorequests.New(http.MethodGet, server.URL+"/status/500").WithExpectStatus().Unmarshal(&resp)
+		as.NotNil(err)
+
+		var httpErr *gorequests.HTTPError
+		as.True(errors.As(err, &httpErr))
+		as.Equal(http.MethodGet, httpErr.Method)
+		as.Equal(server.URL+"/status/500", httpErr.URL)
+		as.Equal(500, httpErr.StatusCode)
+		as.Equal("500", httpErr.Header.Get("X-Status"))
+		as.Len(httpErr.Body, 2048)
+		as.True(strings.HasPrefix(string(httpErr.Body), `{"status":500`))
+	})
+
+	t.Run("expect status set", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithExpectStatus(200, 404))
+
+		_, err := fac.New(http.MethodGet, server.URL+"/status/404").Text()
+		as.Nil(err)
+
+		_, err = fac.New(http.MethodGet, server.URL+"/status/204").Bytes()
+		var httpErr *gorequests.HTTPError
+		as.True(errors.As(err, &httpErr))
+		as.Equal(204, httpErr.StatusCode)
+
+		status, err := fac.New(http.MethodGet, server.URL+"/status/204").ResponseStatus()
+		as.Nil(err)
+		as.Equal(204, status)
+	})
+}
+
+func Test_ErrorKind(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/delay":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second * 3):
+			}
+		case "/json":
+			_, _ = w.Write([]byte("not json"))
+		case "/long":
+			_, _ = w.Write([]byte("not json " + strings.Repeat("x", 1000)))
+		}
+	}))
+	defer server.Close()
+
+	assertKind := func(err error, kind gorequests.ErrorKind, sentinel error) *gorequests.Error {
+		as.NotNil(err)
+		as.Equal(kind, gorequests.KindOf(err), "%s", err)
+		as.True(errors.Is(err, sentinel), "%s", err)
+		var e *gorequests.Error
+		as.True(errors.As(err, &e))
+		as.True(strings.HasPrefix(err.Error(), "gorequests: "), "%s", err)
+		return e
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := gorequests.New(http.MethodGet, server.URL+"/delay").WithTimeout(time.Millisecond * 50).Text()
+		e := assertKind(err, gorequests.KindTimeout, gorequests.ErrTimeout)
+		as.True(e.Timeout())
+		as.True(e.Temporary())
+		as.Equal("send request", e.Op)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+		_, err = gorequests.New(http.MethodGet, server.URL+"/delay").WithContext(ctx).Text()
+		assertKind(err, gorequests.KindTimeout, gorequests.ErrTimeout)
+		as.True(errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := gorequests.New(http.MethodGet, server.URL+"/delay").WithContext(ctx).Text()
+		e := assertKind(err, gorequests.KindCanceled, gorequests.ErrCanceled)
+		as.False(e.Temporary())
+	})
+
+	t.Run("conn refused", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		_, err := gorequests.New(http.MethodGet, closed.URL).Text()
+		assertKind(err, gorequests.KindConnRefused, gorequests.ErrConnRefused)
+	})
+
+	t.Run("dns", func(t *testing.T) {
+		_, err := gorequests.New(http.MethodGet, "http://gorequests.invalid/").Text()
+		assertKind(err, gorequests.KindDNS, gorequests.ErrDNS)
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+		defer tlsServer.Close()
+		_, err := gorequests.New(http.MethodGet, tlsServer.URL).Text()
+		assertKind(err, gorequests.KindTLS, gorequests.ErrTLS)
+
+		_, err = gorequests.New(http.MethodGet, tlsServer.URL).WithIgnoreSSL(true).Text()
+		as.Nil(err)
+
+		// not a tls record
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		as.Nil(err)
+		defer ln.Close()
+		go func() {
+			conn, err := ln.Accept()
+			if err == nil {
+				_, _ = conn.Write([]byte("garbage\n"))
+				_ = conn.Close()
+			}
+		}()
+		_, err = gorequests.New(http.MethodGet, "https://"+ln.Addr().String()).Text()
+		assertKind(err, gorequests.KindTLS, gorequests.ErrTLS)
+
+		_, err = gorequests.New(http.MethodGet, tlsServer.URL).WithTransport(&http.Transport{}).WithIgnoreSSL(true).Text()
+		as.Nil(err)
+
+		// custom transport is kept as is
+		transport := new(captureTransport)
+		_, err = gorequests.New(http.MethodGet, tlsServer.URL).WithTransport(transport).WithIgnoreSSL(true).Text()
+		as.Nil(err)
+		as.NotNil(transport.req)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := gorequests.New(http.MethodGet, server.URL+"/{id}").Text()
+		assertKind(err, gorequests.KindInvalidRequest, gorequests.ErrInvalidRequest)
+		as.True(errors.Is(err, gorequests.ErrUnresolvedPathParam))
+
+		_, err = gorequests.New(http.MethodGet, server.URL).WithQueryStruct(1).Text()
+		assertKind(err, gorequests.KindInvalidRequest, gorequests.ErrInvalidRequest)
+		as.True(errors.Is(err, gorequests.ErrUnsupportedQuery))
+
+		_, err = gorequests.New(http.MethodPost, server.URL).WithJSON(func() {}).Text()
+		assertKind(err, gorequests.KindInvalidRequest, gorequests.ErrInvalidRequest)
+	})
+
+	t.Run("already sent", func(t *testing.T) {
+		r := gorequests.New(http.MethodGet, server.URL)
+		_, err := r.Text()
+		as.Nil(err)
+		_, err = r.WithHeader("a", "b").Text()
+		assertKind(err, gorequests.KindAlreadySent, gorequests.ErrAlreadySent)
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		err := gorequests.New(http.MethodGet, server.URL+"/json").Unmarshal(&map[string]interface{}{})
+		e := assertKind(err, gorequests.KindDecode, gorequests.ErrDecode)
+		as.False(e.Temporary())
+		as.Equal("unmarshal", e.Op)
+		as.Contains(err.Error(), "body: not json")
+
+		_, err = gorequests.New(http.MethodGet, server.URL+"/long").Map()
+		e = assertKind(err, gorequests.KindDecode, gorequests.ErrDecode)
+		as.Equal("unmarshal", e.Op)
+		as.Contains(err.Error(), strings.Repeat("x", 200)+"...")
+		as.NotContains(err.Error(), strings.Repeat("x", 300))
+	})
+
+	t.Run("status", func(t *testing.T) {
+		statusServer := newStatusServer()
+		defer statusServer.Close()
+		_, err := gorequests.New(http.MethodGet, statusServer.URL+"/status/503").WithExpectStatus().Text()
+		as.Equal(gorequests.KindStatus, gorequests.KindOf(err))
+		as.True(errors.Is(err, gorequests.ErrUnexpectedStatus))
+		var httpErr *gorequests.HTTPError
+		as.True(errors.As(err, &httpErr))
+		as.True(httpErr.Temporary())
+		as.False(httpErr.Timeout())
+	})
+
+	t.Run("auth", func(t *testing.T) {
+		_, err := gorequests.New(http.MethodGet, server.URL).WithBearerTokenSource(func(ctx context.Context) (string, error) {
+			return "", errors.New("expired")
+		}).Text()
+		e := assertKind(err, gorequests.KindAuth, gorequests.ErrAuth)
+		as.False(e.Temporary())
+		as.Contains(err.Error(), "get bearer token: expired")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		as.Equal(gorequests.KindUnknown, gorequests.KindOf(errors.New("x")))
+		as.Equal("timeout", gorequests.KindTimeout.String())
+	})
+}
+
+func Test_ErrorResult(t *testing.T) {
+	as := assert.New(t)
+	server := newUserServer()
+	defer server.Close()
+
+	t.Run("UnmarshalResult", func(t *testing.T) {
+		user, errBody := testUser{}, testErrorBody{}
+		as.Nil(gorequests.New(http.MethodGet, server.URL+"/users/1").UnmarshalResult(&user, &errBody))
+		as.Equal(1, user.ID)
+		as.Empty(errBody.Message)
+
+		err := gorequests.New(http.MethodGet, server.URL+"/missing").UnmarshalResult(&user, &errBody)
+		var httpErr *gorequests.HTTPError
+		as.True(errors.As(err, &httpErr))
+		as.Equal(404, httpErr.StatusCode)
+		as.Equal("not found", errBody.Message)
+		as.Equal(&errBody, httpErr.Result)
+	})
+
+	t.Run("expect status", func(t *testing.T) {
+		user, errBody := testUser{}, testErrorBody{}
+		as.Nil(gorequests.New(http.MethodGet, server.URL+"/missing").WithExpectStatus(404).UnmarshalResult(&errBody, &user))
+		as.Equal("not found", errBody.Message)
+	})
+
+	t.Run("WithErrorResult", func(t *testing.T) {
+		errBody := testErrorBody{}
+		err := gorequests.New(http.MethodGet, server.URL+"/missing").WithErrorResult(&errBody).Unmarshal(&testUser{})
+		as.True(errors.Is(err, gorequests.ErrUnexpectedStatus))
+		as.Equal("not found", errBody.Message)
+
+		fac := gorequests.NewFactory(gorequests.WithErrorResult(&testErrorBody{}))
+		err = fac.New(http.MethodGet, server.URL+"/missing").Unmarshal(&testUser{})
+		var httpErr *gorequests.HTTPError
+		as.True(errors.As(err, &httpErr))
+		as.Equal(&testErrorBody{Message: "not found"}, httpErr.Result)
+
+		user := testUser{}
+		as.Nil(fac.New(http.MethodGet, server.URL+"/users/1").Unmarshal(&user))
+		as.Equal(1, user.ID)
+
+		_, err = gorequests.NewFactory(gorequests.WithErrorResult(testErrorBody{})).New(http.MethodGet, server.URL).Text()
+		as.NotNil(err)
+	})
+}
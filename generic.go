@@ -0,0 +1,102 @@
+package gorequests
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Requester create request, implemented by *Factory and *Session
+type Requester interface {
+	New(method, url string) *Request
+}
+
+// Response is the decoded response of Do
+type Response[T any] struct {
+	Data       T
+	StatusCode int
+	Header     http.Header
+	Request    *Request
+}
+
+// Result is the decoded response of DoResult, ErrorBody is set when status is not expected
+type Result[T, E any] struct {
+	Response[T]
+	ErrorBody *E
+}
+
+// JSON send request and decode response body as T
+//
+//	user, err := gorequests.JSON[User](gorequests.New(http.MethodGet, "https://api/users/1"))
+func JSON[T any](req *Request) (T, error) {
+	var val T
+	if err := req.Unmarshal(&val); err != nil {
+		return val, err
+	}
+	return val, nil
+}
+
+// Do send request and decode response body as T, with status and header
+func Do[T any](req *Request) (*Response[T], error) {
+	data, err := JSON[T](req)
+	if err != nil {
+		return nil, err
+	}
+	return &Response[T]{Data: data, StatusCode: req.resp.StatusCode, Header: req.resp.Header, Request: req}, nil
+}
+
+// DoResult send request, decode response body as T when status is expected, see WithExpectStatus,
+// or as E with *HTTPError returned when status is not expected, any 2xx status is expected by default.
+func DoResult[T, E any](req *Request) (*Result[T, E], error) {
+	res := &Result[T, E]{}
+	errBody := new(E)
+	err := req.UnmarshalResult(&res.Data, errBody)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.Result != nil {
+			res.ErrorBody = errBody
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	res.Response.StatusCode, res.Response.Header, res.Response.Request = req.resp.StatusCode, req.resp.Header, req
+	return res, err
+}
+
+// Get send GET request created by c, and decode response body as T
+func Get[T any](c Requester, url string, options ...RequestOption) (T, error) {
+	return JSON[T](newRequestWithOptions(c, http.MethodGet, url, nil, options))
+}
+
+// Post send POST request created by c with json body, and decode response body as T
+func Post[T any](c Requester, url string, body interface{}, options ...RequestOption) (T, error) {
+	return JSON[T](newRequestWithOptions(c, http.MethodPost, url, body, options))
+}
+
+// Put send PUT request created by c with json body, and decode response body as T
+func Put[T any](c Requester, url string, body interface{}, options ...RequestOption) (T, error) {
+	return JSON[T](newRequestWithOptions(c, http.MethodPut, url, body, options))
+}
+
+// Patch send PATCH request created by c with json body, and decode response body as T
+func Patch[T any](c Requester, url string, body interface{}, options ...RequestOption) (T, error) {
+	return JSON[T](newRequestWithOptions(c, http.MethodPatch, url, body, options))
+}
+
+// Delete send DELETE request created by c, and decode response body as T
+func Delete[T any](c Requester, url string, options ...RequestOption) (T, error) {
+	return JSON[T](newRequestWithOptions(c, http.MethodDelete, url, nil, options))
+}
+
+func newRequestWithOptions(c Requester, method, url string, body interface{}, options []RequestOption) *Request {
+	req := c.New(method, url)
+	if body != nil {
+		req.WithJSON(body)
+	}
+	for _, v := range options {
+		if err := v(req); err != nil {
+			return req.SetError(err)
+		}
+	}
+	return req
+}
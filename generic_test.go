@@ -0,0 +1,110 @@
+package gorequests_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testErrorBody struct {
+	Message string `json:"message"`
+}
+
+func newUserServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users/1":
+			if r.Method == http.MethodGet || r.Method == http.MethodDelete {
+				_, _ = w.Write([]byte(`{"id":1,"name":"` + r.URL.Query().Get("name") + `"}`))
+				return
+			}
+			bs, _ := ioutil.ReadAll(r.Body)
+			_, _ = w.Write(bs)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(testErrorBody{Message: "not found"})
+		}
+	}))
+}
+
+func Test_Generic(t *testing.T) {
+	as := assert.New(t)
+	server := newUserServer()
+	defer server.Close()
+
+	t.Run("JSON", func(t *testing.T) {
+		user, err := gorequests.JSON[testUser](gorequests.New(http.MethodGet, server.URL+"/users/1").WithQuery("name", "a"))
+		as.Nil(err)
+		as.Equal(testUser{ID: 1, Name: "a"}, user)
+
+		m, err := gorequests.JSON[map[string]interface{}](gorequests.New(http.MethodGet, server.URL+"/users/1"))
+		as.Nil(err)
+		as.Equal(float64(1), m["id"])
+	})
+
+	t.Run("Do", func(t *testing.T) {
+		resp, err := gorequests.Do[testUser](gorequests.New(http.MethodGet, server.URL+"/users/1"))
+		as.Nil(err)
+		as.Equal(200, resp.StatusCode)
+		as.Equal("application/json", resp.Header.Get("Content-Type"))
+		as.Equal(1, resp.Data.ID)
+
+		_, err = gorequests.Do[testUser](gorequests.New(http.MethodGet, server.URL+"/missing").WithExpectStatus())
+		as.True(errors.Is(err, gorequests.ErrUnexpectedStatus))
+	})
+
+	t.Run("DoResult", func(t *testing.T) {
+		res, err := gorequests.DoResult[testUser, testErrorBody](gorequests.New(http.MethodGet, server.URL+"/users/1"))
+		as.Nil(err)
+		as.Nil(res.ErrorBody)
+		as.Equal(1, res.Data.ID)
+
+		res, err = gorequests.DoResult[testUser, testErrorBody](gorequests.New(http.MethodGet, server.URL+"/missing"))
+		var httpErr *gorequests.HTTPError
+		as.True(errors.As(err, &httpErr))
+		as.Equal(404, httpErr.StatusCode)
+		as.Equal(404, res.StatusCode)
+		as.Equal("not found", res.ErrorBody.Message)
+	})
+
+	t.Run("Factory helpers", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithBaseURL(server.URL), gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+		user, err := gorequests.Get[testUser](fac, "/users/1", gorequests.WithQuery("name", "get"))
+		as.Nil(err)
+		as.Equal("get", user.Name)
+
+		user, err = gorequests.Post[testUser](fac, "/users/1", testUser{ID: 2, Name: "post"})
+		as.Nil(err)
+		as.Equal(testUser{ID: 2, Name: "post"}, user)
+
+		user, err = gorequests.Put[testUser](fac, "/users/1", testUser{ID: 3})
+		as.Nil(err)
+		as.Equal(3, user.ID)
+
+		user, err = gorequests.Patch[testUser](fac, "/users/1", testUser{ID: 4})
+		as.Nil(err)
+		as.Equal(4, user.ID)
+
+		user, err = gorequests.Delete[testUser](fac, "/users/1")
+		as.Nil(err)
+		as.Equal(1, user.ID)
+
+		s := gorequests.NewSession(t.TempDir()+"/cookie.json", gorequests.WithBaseURL(server.URL))
+		user, err = gorequests.Get[testUser](s, "/users/1")
+		as.Nil(err)
+		as.Equal(1, user.ID)
+	})
+}
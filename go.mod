@@ -1,14 +1,18 @@
 module github.com/jloha/gorequests
 
-go 1.16
+go 1.18
 
 require (
 	github.com/bitholic/gorequests v0.39.0
 	github.com/chyroc/persistent-cookiejar v0.1.0
 	github.com/stretchr/testify v1.8.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/google/go-cmp v0.5.7 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.0.0-20210916014120-12bc252f5db8 // indirect
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 )
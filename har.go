@@ -0,0 +1,506 @@
+package gorequests
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// harRedacted replace value of redacted data in HAR
+const harRedacted = "REDACTED"
+
+// HAR is HTTP Archive 1.2, see http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Entries []*HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"` // milliseconds
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []HARNameValue `json:"params,omitempty"`
+	Text     string         `json:"text"`
+}
+
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"` // base64 if body is not utf-8
+}
+
+// HARTimings is timings of entry in milliseconds, -1 if it does not apply or is unknown
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HARRedaction redact sensitive data of HAR entry, redacted value is replaced with "REDACTED"
+type HARRedaction struct {
+	Headers     []string                                  // request and response headers, Cookie and Set-Cookie also redact cookies
+	QueryParams []string                                  // query params of url and queryString
+	Body        func(mimeType string, text string) string // rewrite request and response body text
+}
+
+// Redact redact entry in place
+func (e *HAREntry) Redact(redaction HARRedaction) *HAREntry {
+	for _, name := range redaction.Headers {
+		redactHARNameValues(e.Request.Headers, name, true)
+		redactHARNameValues(e.Response.Headers, name, true)
+		switch http.CanonicalHeaderKey(name) {
+		case "Cookie":
+			for i := range e.Request.Cookies {
+				e.Request.Cookies[i].Value = harRedacted
+			}
+		case "Set-Cookie":
+			for i := range e.Response.Cookies {
+				e.Response.Cookies[i].Value = harRedacted
+			}
+		}
+	}
+
+	if len(redaction.QueryParams) > 0 {
+		for _, name := range redaction.QueryParams {
+			redactHARNameValues(e.Request.QueryString, name, false)
+		}
+		if u, err := url.Parse(e.Request.URL); err == nil {
+			query := u.Query()
+			for _, name := range redaction.QueryParams {
+				if vs, ok := query[name]; ok {
+					for i := range vs {
+						vs[i] = harRedacted
+					}
+				}
+			}
+			u.RawQuery = query.Encode()
+			e.Request.URL = u.String()
+		}
+	}
+
+	if redaction.Body != nil {
+		if e.Request.PostData != nil {
+			e.Request.PostData.Text = redaction.Body(e.Request.PostData.MimeType, e.Request.PostData.Text)
+			for i := range e.Request.PostData.Params {
+				e.Request.PostData.Params[i].Value = harRedacted
+			}
+		}
+		if e.Response.Content.Encoding == "" && e.Response.Content.Text != "" {
+			e.Response.Content.Text = redaction.Body(e.Response.Content.MimeType, e.Response.Content.Text)
+		}
+	}
+	return e
+}
+
+// ToHAREntry build HAR entry of request, it send request and read response if not yet,
+// timings only has wait time, use HARRecorder for detail timings
+func (r *Request) ToHAREntry() (*HAREntry, error) {
+	if err := r.doRead(); err != nil {
+		return nil, err
+	}
+
+	message := r.newLogMessage()
+	return newHAREntry(&harExchange{
+		method:     message.Method,
+		url:        message.Url,
+		reqHeader:  message.RequestHeader,
+		reqBody:    []byte(message.RequestBody),
+		resp:       r.resp,
+		respBody:   []byte(message.ResponseBody),
+		start:      r.reqTime,
+		total:      r.respTime.Sub(r.reqTime),
+		timings:    HARTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: msOf(r.respTime.Sub(r.reqTime)), Receive: 0, SSL: -1},
+		errMessage: message.ErrorMessage,
+	}), nil
+}
+
+// HARRecorder record every round trip of requests as HAR entry, include redirects and cache hits,
+// entry is recorded when response body is read to EOF or closed
+type HARRecorder struct {
+	redaction HARRedaction
+
+	lock    sync.Mutex
+	entries []*HAREntry
+}
+
+// harDefaultRedactHeaders carry credentials, they are always redacted by HARRecorder
+var harDefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// NewHARRecorder create HAR recorder, see WithHARRecorder,
+// Authorization, Proxy-Authorization and Cookie are redacted in addition to headers of redaction
+func NewHARRecorder(redaction HARRedaction) *HARRecorder {
+	redaction.Headers = append(append([]string(nil), harDefaultRedactHeaders...), redaction.Headers...)
+	return &HARRecorder{redaction: redaction}
+}
+
+// HAR return recorded entries as HAR
+func (h *HARRecorder) HAR() *HAR {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "gorequests", Version: version},
+		Entries: append([]*HAREntry{}, h.entries...),
+	}}
+}
+
+// WriteTo write HAR json to w
+func (h *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	bs, err := json.MarshalIndent(h.HAR(), "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(bs)
+	return int64(n), err
+}
+
+// WriteFile write HAR json to file
+func (h *HARRecorder) WriteFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if _, err = h.WriteTo(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Reset remove recorded entries
+func (h *HARRecorder) Reset() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.entries = nil
+}
+
+func (h *HARRecorder) add(entry *HAREntry) {
+	entry.Redact(h.redaction)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.entries = append(h.entries, entry)
+}
+
+type harTransport struct {
+	recorder *HARRecorder
+	next     http.RoundTripper
+}
+
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	x := &harExchange{
+		method:    req.Method,
+		url:       req.URL.String(),
+		reqHeader: req.Header.Clone(),
+		reqBody:   reqBody,
+		start:     time.Now(),
+		timings:   HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), x.trace()))
+
+	resp, err := t.next.RoundTrip(req)
+	x.gotHeaders = time.Now()
+	if err != nil {
+		x.errMessage = err.Error()
+		t.recorder.add(x.entry())
+		return nil, err
+	}
+	x.resp = resp
+	resp.Body = &harBody{ReadCloser: resp.Body, done: func(body []byte) {
+		x.respBody = body
+		t.recorder.add(x.entry())
+	}}
+	return resp, nil
+}
+
+// harExchange is the data of one round trip
+type harExchange struct {
+	method     string
+	url        string
+	reqHeader  http.Header
+	reqBody    []byte
+	resp       *http.Response
+	respBody   []byte
+	start      time.Time
+	total      time.Duration
+	timings    HARTimings
+	errMessage string
+
+	// trace
+	lock                          sync.Mutex
+	dnsStart, connStart, tlsStart time.Time
+	wroteHeaders, wroteRequest    time.Time
+	gotHeaders                    time.Time
+}
+
+func (x *harExchange) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { x.setTime(&x.dnsStart) },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			x.lock.Lock()
+			x.timings.DNS = msOf(time.Since(x.dnsStart))
+			x.lock.Unlock()
+		},
+		ConnectStart: func(string, string) { x.setTime(&x.connStart) },
+		ConnectDone: func(string, string, error) {
+			x.lock.Lock()
+			x.timings.Connect = msOf(time.Since(x.connStart))
+			x.lock.Unlock()
+		},
+		TLSHandshakeStart: func() { x.setTime(&x.tlsStart) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			x.lock.Lock()
+			x.timings.SSL = msOf(time.Since(x.tlsStart))
+			x.lock.Unlock()
+		},
+		WroteHeaders:         func() { x.setTime(&x.wroteHeaders) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { x.setTime(&x.wroteRequest) },
+		GotFirstResponseByte: func() { x.setTime(&x.gotHeaders) },
+	}
+}
+
+func (x *harExchange) setTime(t *time.Time) {
+	x.lock.Lock()
+	*t = time.Now()
+	x.lock.Unlock()
+}
+
+// entry finish timings of round trip and build entry, called when response body is read
+func (x *harExchange) entry() *HAREntry {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	now := time.Now()
+	if !x.wroteRequest.IsZero() && !x.wroteHeaders.IsZero() {
+		x.timings.Send = msOf(x.wroteRequest.Sub(x.wroteHeaders))
+		x.timings.Wait = msOf(x.gotHeaders.Sub(x.wroteRequest))
+	} else {
+		// no connection is used, e.g. response from cache
+		x.timings.Wait = msOf(x.gotHeaders.Sub(x.start))
+	}
+	x.timings.Receive = msOf(now.Sub(x.gotHeaders))
+	x.total = now.Sub(x.start)
+	return newHAREntry(x)
+}
+
+func newHAREntry(x *harExchange) *HAREntry {
+	entry := &HAREntry{
+		StartedDateTime: x.start.Format(time.RFC3339Nano),
+		Time:            msOf(x.total),
+		Timings:         x.timings,
+		Comment:         x.errMessage,
+		Request: HARRequest{
+			Method:      x.method,
+			URL:         x.url,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     harRequestCookies(x.reqHeader),
+			Headers:     harHeaders(x.reqHeader),
+			QueryString: []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    int64(len(x.reqBody)),
+		},
+		Response: HARResponse{
+			Cookies:     []HARCookie{},
+			Headers:     []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+	}
+	for _, v := range []*float64{&entry.Timings.Send, &entry.Timings.Wait, &entry.Timings.Receive} {
+		if *v < 0 {
+			*v = 0
+		}
+	}
+	if u, err := url.Parse(x.url); err == nil {
+		entry.Request.QueryString = harNameValues(u.Query())
+	}
+	if len(x.reqBody) > 0 {
+		mimeType := x.reqHeader.Get("Content-Type")
+		entry.Request.PostData = &HARPostData{MimeType: mimeType, Text: string(x.reqBody)}
+		if mediaType, _, _ := mime.ParseMediaType(mimeType); mediaType == "application/x-www-form-urlencoded" {
+			if values, err := url.ParseQuery(string(x.reqBody)); err == nil {
+				entry.Request.PostData.Params = harNameValues(values)
+			}
+		}
+	}
+
+	if resp := x.resp; resp != nil {
+		entry.Request.HTTPVersion = resp.Proto
+		entry.Response.Status = resp.StatusCode
+		entry.Response.StatusText = strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode)))
+		entry.Response.HTTPVersion = resp.Proto
+		entry.Response.Headers = harHeaders(resp.Header)
+		entry.Response.RedirectURL = resp.Header.Get("Location")
+		entry.Response.BodySize = int64(len(x.respBody))
+		entry.Response.Content = HARContent{Size: int64(len(x.respBody)), MimeType: resp.Header.Get("Content-Type")}
+		if utf8.Valid(x.respBody) {
+			entry.Response.Content.Text = string(x.respBody)
+		} else {
+			entry.Response.Content.Text = base64.StdEncoding.EncodeToString(x.respBody)
+			entry.Response.Content.Encoding = "base64"
+		}
+		for _, c := range resp.Cookies() {
+			cookie := HARCookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain, HTTPOnly: c.HttpOnly, Secure: c.Secure}
+			if !c.Expires.IsZero() {
+				cookie.Expires = c.Expires.Format(time.RFC3339)
+			}
+			entry.Response.Cookies = append(entry.Response.Cookies, cookie)
+		}
+	}
+	return entry
+}
+
+// harBody call done with read body once, when body is read to EOF or closed
+type harBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func(body []byte)
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *harBody) finish() {
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+}
+
+func harHeaders(header http.Header) []HARNameValue {
+	res := []HARNameValue{}
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			res = append(res, HARNameValue{Name: k, Value: v})
+		}
+	}
+	return res
+}
+
+func harNameValues(values url.Values) []HARNameValue {
+	return harHeaders(http.Header(values))
+}
+
+func harRequestCookies(header http.Header) []HARCookie {
+	res := []HARCookie{}
+	for _, c := range (&http.Request{Header: header}).Cookies() {
+		res = append(res, HARCookie{Name: c.Name, Value: c.Value})
+	}
+	return res
+}
+
+func redactHARNameValues(list []HARNameValue, name string, ignoreCase bool) {
+	for i := range list {
+		if list[i].Name == name || (ignoreCase && strings.EqualFold(list[i].Name, name)) {
+			list[i].Value = harRedacted
+		}
+	}
+}
+
+func msOf(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
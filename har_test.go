@@ -0,0 +1,72 @@
+package gorequests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HAR(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("ToHAREntry", func(t *testing.T) {
+		r := gorequests.New(http.MethodPost, joinHttpBinURL("/post")).
+			WithQuery("a", "1").
+			WithHeader("Cookie", "c=d").
+			WithFormURLEncoded(map[string]string{"name": "bob"})
+		entry, err := r.ToHAREntry()
+		as.Nil(err)
+		as.Equal(http.MethodPost, entry.Request.Method)
+		as.Equal(joinHttpBinURL("/post?a=1"), entry.Request.URL)
+		as.Equal([]gorequests.HARNameValue{{Name: "a", Value: "1"}}, entry.Request.QueryString)
+		as.Equal([]gorequests.HARCookie{{Name: "c", Value: "d"}}, entry.Request.Cookies)
+		as.Equal([]gorequests.HARNameValue{{Name: "name", Value: "bob"}}, entry.Request.PostData.Params)
+		as.Equal(200, entry.Response.Status)
+		as.Equal("OK", entry.Response.StatusText)
+		as.Contains(entry.Response.Content.Text, `"name": "bob"`)
+		as.Equal("application/json", entry.Response.Content.MimeType)
+		as.True(entry.Timings.Wait >= 0)
+		as.Equal(float64(-1), entry.Timings.DNS)
+	})
+
+	t.Run("recorder", func(t *testing.T) {
+		rec := gorequests.NewHARRecorder(gorequests.HARRedaction{
+			Headers:     []string{"Set-Cookie"}, // Authorization, Proxy-Authorization and Cookie are redacted by default
+			QueryParams: []string{"token"},
+			Body: func(mimeType, text string) string {
+				return strings.ReplaceAll(text, "secret", "REDACTED")
+			},
+		})
+		s := gorequests.NewSession(t.TempDir()+"/cookie.json", gorequests.WithHARRecorder(rec), gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+		_, err := s.New(http.MethodGet, joinHttpBinURL("/cookies/set?k=v&token=secret")).
+			WithHeader("Authorization", "Bearer secret").
+			Text()
+		as.Nil(err)
+
+		har := rec.HAR()
+		as.Equal("1.2", har.Log.Version)
+		as.Equal("gorequests", har.Log.Creator.Name)
+		as.Len(har.Log.Entries, 2)
+
+		redirect, cookies := har.Log.Entries[0], har.Log.Entries[1]
+		as.Equal(http.StatusFound, redirect.Response.Status)
+		as.Equal("/cookies", redirect.Response.RedirectURL)
+		as.ElementsMatch([]gorequests.HARCookie{{Name: "k", Value: "REDACTED", Path: "/"}, {Name: "token", Value: "REDACTED", Path: "/"}}, redirect.Response.Cookies)
+		as.Contains(redirect.Request.URL, "token=REDACTED")
+		as.Equal(joinHttpBinURL("/cookies"), cookies.Request.URL)
+		as.ElementsMatch([]gorequests.HARCookie{{Name: "k", Value: "REDACTED"}, {Name: "token", Value: "REDACTED"}}, cookies.Request.Cookies)
+		as.True(cookies.Time > 0)
+
+		buf := new(bytes.Buffer)
+		_, err = rec.WriteTo(buf)
+		as.Nil(err)
+		as.NotContains(buf.String(), "Bearer secret")
+		as.True(json.Valid(buf.Bytes()))
+	})
+}
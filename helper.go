@@ -7,10 +7,8 @@ import (
 	"io"
 	"mime/multipart"
 	"net/url"
-	"reflect"
-	"strconv"
+	"regexp"
 	"strings"
-	"sync"
 )
 
 func newFileUploadRequest(params map[string]string, filekey, filename string, reader io.Reader) (string, io.Reader, error) {
@@ -37,112 +35,129 @@ func newFileUploadRequest(params map[string]string, filekey, filename string, re
 	return writer.FormDataContentType(), body, nil
 }
 
-func queryToMap(v interface{}) (map[string][]string, error) {
-	ss, err := getQueryToMapKeys(v)
+// request url
+func (r *Request) parseRequestURL() string {
+	u, err := r.buildRequestURL()
 	if err != nil {
-		return nil, err
-	} else if len(ss) == 0 {
-		return map[string][]string{}, nil
+		return r.url
 	}
+	return u
+}
 
-	vv := reflect.ValueOf(v)
-	if vv.Kind() == reflect.Ptr {
-		vv = vv.Elem()
+func (r *Request) buildRequestURL() (string, error) {
+	if r.fullUrl != "" {
+		return r.fullUrl, nil
 	}
 
-	vals := map[string][]string{}
-	for _, s := range ss {
-		vals[s.query], err = toStringList(vv.Field(s.idx))
-		if err != nil {
-			return nil, err
-		}
+	rawURL, err := renderPathTemplate(r.url, r.pathParams)
+	if err != nil {
+		return "", err
 	}
 
-	return vals, nil
-}
-
-func toStringList(v reflect.Value) ([]string, error) {
-	switch v.Kind() {
-	case reflect.String:
-		return []string{v.String()}, nil
-	case reflect.Bool:
-		if v.Bool() {
-			return []string{"true"}, nil
-		}
-		return []string{"false"}, nil
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return []string{strconv.FormatInt(v.Int(), 10)}, nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return []string{strconv.FormatUint(v.Uint(), 10)}, nil
-	case reflect.Array, reflect.Slice:
-		res := []string{}
-		for j := 0; j < v.Len(); j++ {
-			x, err := toStringList(v.Index(j))
-			if err != nil {
-				return nil, err
-			}
-			res = append(res, x...)
+	URL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if r.baseURL != "" {
+		if URL, err = resolveBaseURL(r.baseURL, URL, r.isAllowBaseURLEscape); err != nil {
+			return "", err
 		}
-		return res, nil
 	}
-
-	return nil, fmt.Errorf("invalid value: %s", v.Kind())
+	q := URL.Query()
+	for k, v := range r.querys {
+		q[k] = append(q[k], v...)
+	}
+	URL.RawQuery = q.Encode()
+	return URL.String(), nil
 }
 
-func getQueryToMapKeys(v interface{}) ([]s, error) {
-	origin := reflect.TypeOf(v)
-	v, ok := queryToMapKeys.Load(origin)
-	if ok {
-		return v.([]s), nil
+// resolveBaseURL resolve ref against base with RFC 3986 semantics, and merge query of base which not exist in ref,
+// query of base is not sent to other scheme or host
+func resolveBaseURL(base string, ref *url.URL, allowEscape bool) (*url.URL, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url %s: %w", base, err)
 	}
 
-	vt := origin
-	// vv := reflect.ValueOf(v)
-	if vt.Kind() == reflect.Ptr {
-		vt = vt.Elem()
-		// vv = vv.Elem()
-	}
-	if vt.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("need strcut, but got %s", vt.Kind())
+	URL := baseURL.ResolveReference(ref)
+	if URL.Scheme != baseURL.Scheme || !strings.EqualFold(URL.Host, baseURL.Host) {
+		if !allowEscape {
+			return nil, fmt.Errorf("url %s %w %s", ref, ErrBaseURLEscape, base)
+		}
+		return URL, nil
 	}
 
-	ss := []s{}
-	for i := 0; i < vt.NumField(); i++ {
-		itemT := vt.Field(i)
-		// itemV := vv.Field(i)
-
-		queryKey := itemT.Tag.Get("query")
-		if queryKey == "" {
-			continue
+	q := URL.Query()
+	for k, v := range baseURL.Query() {
+		if _, ok := q[k]; !ok {
+			q[k] = v
 		}
-		// itemV.String()
-		ss = append(ss, s{
-			idx:   i,
-			query: queryKey,
-		})
 	}
+	URL.RawQuery = q.Encode()
+	return URL, nil
+}
 
-	queryToMapKeys.Store(origin, ss)
+// renderPathTemplate replace {name} placeholders in the path of the url with escaped path params,
+// placeholders in scheme, host, query and fragment are kept as is, "." and ".." are rejected as they move up the path
+func renderPathTemplate(rawURL string, params map[string]string) (string, error) {
+	path, rest := rawURL, ""
+	if idx := strings.IndexAny(rawURL, "?#"); idx >= 0 {
+		path, rest = rawURL[:idx], rawURL[idx:]
+	}
+	// skip scheme and authority of absolute url
+	prefix := ""
+	if idx := strings.Index(path, "//"); idx >= 0 && !strings.Contains(path[:idx], "/") && (idx == 0 || path[idx-1] == ':') {
+		end := len(path)
+		if i := strings.Index(path[idx+2:], "/"); i >= 0 {
+			end = idx + 2 + i
+		}
+		prefix, path = path[:end], path[end:]
+	}
+	if !strings.Contains(path, "{") {
+		return rawURL, nil
+	}
 
-	return ss, nil
+	var unresolved, invalid []string
+	path = pathParamRegexp.ReplaceAllStringFunc(path, func(s string) string {
+		name := s[1 : len(s)-1]
+		v, ok := params[name]
+		if !ok {
+			unresolved = append(unresolved, s)
+			return s
+		}
+		if v == "." || v == ".." {
+			invalid = append(invalid, fmt.Sprintf("%s=%q", s, v))
+		}
+		return url.PathEscape(v)
+	})
+	if len(unresolved) > 0 {
+		return "", fmt.Errorf("%w %s in %s", ErrUnresolvedPathParam, strings.Join(unresolved, ", "), rawURL)
+	}
+	if len(invalid) > 0 {
+		return "", fmt.Errorf("%w %s in %s: dot segment is not allowed", ErrInvalidPathParam, strings.Join(invalid, ", "), rawURL)
+	}
+	return prefix + path + rest, nil
 }
 
-// request url
-func (r *Request) parseRequestURL() string {
-	if r.fullUrl != "" {
-		return r.fullUrl
+// isRoute report whether request match method and url of route, url is compared without query,
+// and only path is compared if route has no host
+func (r *Request) isRoute(method, route string) bool {
+	if !strings.EqualFold(r.method, method) {
+		return false
 	}
-
-	URL, err := url.Parse(r.url)
+	routeURL, err := url.Parse(route)
 	if err != nil {
-		return r.url
+		return false
 	}
-	q := URL.Query()
-	for k, v := range r.querys {
-		q[k] = append(q[k], v...)
+	reqURL, err := url.Parse(r.url)
+	if err != nil {
+		return false
 	}
-	URL.RawQuery = q.Encode()
-	return URL.String()
+	if routeURL.Host == "" {
+		return strings.TrimSuffix(routeURL.Path, "/") == strings.TrimSuffix(reqURL.Path, "/")
+	}
+	return routeURL.Scheme == reqURL.Scheme && strings.EqualFold(routeURL.Host, reqURL.Host) &&
+		strings.TrimSuffix(routeURL.Path, "/") == strings.TrimSuffix(reqURL.Path, "/")
 }
 
 func toBody(body interface{}) ([]byte, io.Reader, error) {
@@ -162,9 +177,4 @@ func toBody(body interface{}) ([]byte, io.Reader, error) {
 	}
 }
 
-type s struct {
-	idx   int
-	query string
-}
-
-var queryToMapKeys sync.Map
+var pathParamRegexp = regexp.MustCompile(`\{[A-Za-z_][A-Za-z0-9_]*\}`)
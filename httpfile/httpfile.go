@@ -0,0 +1,373 @@
+// Package httpfile parse .http request files of VS Code REST Client and JetBrains HTTP Client,
+// and run them with gorequests.
+//
+//	@host = https://api.example.com
+//
+//	### login
+//	# @name login
+//	POST {{host}}/login
+//	Content-Type: application/json
+//
+//	< ./login.json
+//
+//	###
+//	GET {{host}}/users?page=1
+//	Authorization: Bearer {{login.response.body.$.token}}
+//
+// supported syntax:
+//
+//	###                                  request separator, text after it is the request name
+//	# @name name                         request name, used to reference response of request
+//	@var = value                         file variable
+//	{{var}}                              variable of request, file and environment
+//	{{$timestamp}}, {{$randomInt a b}}, {{$processEnv NAME}}, {{$guid}}   system variables
+//	{{name.response.body.<json path>}}   json path of response body of named request, see gorequests.Request.JSONPath
+//	{{name.response.headers.<name>}}     response header of named request
+//	< ./file                             body from file, path is relative to .http file
+//	<@ ./file                            body from file, and variables in it are resolved
+//	# and //                             comment lines
+package httpfile
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jloha/gorequests"
+)
+
+// maxVariableDepth limit nested reference of variables
+const maxVariableDepth = 10
+
+var (
+	variableRegexp    = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
+	requestLineRegexp = regexp.MustCompile(`^(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|TRACE|CONNECT)\s+(.+?)(\s+HTTP/[0-9.]+)?$`)
+	variableDefRegexp = regexp.MustCompile(`^@([A-Za-z_][A-Za-z0-9_.-]*)\s*=\s*(.*)$`)
+	nameRegexp        = regexp.MustCompile(`^(?:#|//)\s*@name\s+(\S+)`)
+)
+
+// File is a parsed .http file
+type File struct {
+	Dir       string            // directory of file, body file path is relative to it
+	Variables map[string]string // file variables, value is not resolved
+	Requests  []*Request
+}
+
+// Request is a request of .http file, fields are not resolved
+type Request struct {
+	Name     string
+	Line     int // line number of request line
+	Method   string
+	URL      string
+	Header   [][2]string
+	Body     string
+	BodyFile string // body file, Body is ignored if it's set
+	// BodyFileVariables resolve variables in body file, <@ syntax
+	BodyFileVariables bool
+}
+
+// ParseFile parse .http file
+func ParseFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("httpfile: %w", err)
+	}
+	defer f.Close()
+
+	return Parse(f, filepath.Dir(path))
+}
+
+// Parse parse .http content, dir is the directory which body file path is relative to
+func Parse(reader io.Reader, dir string) (*File, error) {
+	file := &File{Dir: dir, Variables: map[string]string{}}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	const (
+		statePreamble = iota
+		stateQuery
+		stateHeader
+		stateBody
+	)
+	var (
+		state    = statePreamble
+		cur      *Request
+		name     string
+		body     []string
+		lineNo   int
+		finished = func() {
+			if cur != nil {
+				cur.Body = strings.TrimRight(strings.Join(body, "\n"), "\n\r\t ")
+				file.Requests = append(file.Requests, cur)
+			}
+			cur, name, body, state = nil, "", nil, statePreamble
+		}
+	)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "###") {
+			finished()
+			name = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+
+		switch state {
+		case statePreamble:
+			switch {
+			case trimmed == "":
+			case nameRegexp.MatchString(trimmed):
+				name = nameRegexp.FindStringSubmatch(trimmed)[1]
+			case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//"):
+			case variableDefRegexp.MatchString(trimmed):
+				match := variableDefRegexp.FindStringSubmatch(trimmed)
+				file.Variables[match[1]] = strings.TrimSpace(match[2])
+			default:
+				cur = &Request{Name: name, Line: lineNo}
+				if match := requestLineRegexp.FindStringSubmatch(trimmed); match != nil {
+					cur.Method, cur.URL = match[1], strings.TrimSpace(match[2])
+				} else {
+					cur.Method, cur.URL = http.MethodGet, strings.TrimSpace(strings.Split(trimmed, " HTTP/")[0])
+				}
+				state = stateQuery
+			}
+		case stateQuery, stateHeader:
+			switch {
+			case trimmed == "":
+				state = stateBody
+			case state == stateQuery && (strings.HasPrefix(trimmed, "?") || strings.HasPrefix(trimmed, "&")):
+				cur.URL += trimmed
+			case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//"):
+			default:
+				state = stateHeader
+				idx := strings.Index(trimmed, ":")
+				if idx <= 0 {
+					return nil, fmt.Errorf("httpfile: line %d: invalid header %q", lineNo, trimmed)
+				}
+				cur.Header = append(cur.Header, [2]string{strings.TrimSpace(trimmed[:idx]), strings.TrimSpace(trimmed[idx+1:])})
+			}
+		case stateBody:
+			if len(body) == 0 && cur.BodyFile == "" {
+				switch {
+				case trimmed == "":
+					continue
+				case strings.HasPrefix(trimmed, "<@"):
+					cur.BodyFile, cur.BodyFileVariables = strings.TrimSpace(trimmed[2:]), true
+					continue
+				case strings.HasPrefix(trimmed, "< "):
+					cur.BodyFile = strings.TrimSpace(trimmed[1:])
+					continue
+				}
+			}
+			body = append(body, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("httpfile: %w", err)
+	}
+	finished()
+	return file, nil
+}
+
+// LoadEnv load variables of env from environment file, which is a json object of env name to variables,
+// like http-client.env.json of JetBrains, variables of "$shared" are shared by all envs
+func LoadEnv(path, env string) (map[string]string, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("httpfile: %w", err)
+	}
+	envs := map[string]map[string]interface{}{}
+	if err := json.Unmarshal(bs, &envs); err != nil {
+		return nil, fmt.Errorf("httpfile: parse env file %s: %w", path, err)
+	}
+	if _, ok := envs[env]; !ok {
+		return nil, fmt.Errorf("httpfile: env %q not found in %s", env, path)
+	}
+
+	res := map[string]string{}
+	for _, name := range []string{"$shared", env} {
+		for k, v := range envs[name] {
+			if s, ok := v.(string); ok {
+				res[k] = s
+			} else {
+				res[k] = fmt.Sprint(v)
+			}
+		}
+	}
+	return res, nil
+}
+
+// Build resolve variables of request, and create request with requester, env is the environment variables,
+// responses is the named requests which have been sent, see Runner
+func (f *File) Build(requester gorequests.Requester, req *Request, env map[string]string, responses map[string]*gorequests.Request) (*gorequests.Request, error) {
+	resolver := &resolver{file: f, env: env, responses: responses}
+	wrap := func(err error) error {
+		return fmt.Errorf("httpfile: line %d: %w", req.Line, err)
+	}
+
+	rawURL, err := resolver.resolve(req.URL, 0)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	body := req.Body
+	if req.BodyFile != "" {
+		path := req.BodyFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(f.Dir, path)
+		}
+		bs, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, wrap(err)
+		}
+		body = string(bs)
+	}
+	if req.BodyFile == "" || req.BodyFileVariables {
+		if body, err = resolver.resolve(body, 0); err != nil {
+			return nil, wrap(err)
+		}
+	}
+
+	r := requester.New(req.Method, rawURL)
+	for _, kv := range req.Header {
+		v, err := resolver.resolve(kv[1], 0)
+		if err != nil {
+			return nil, wrap(err)
+		}
+		r.WithHeader(kv[0], v)
+	}
+	if body != "" {
+		r.WithBody(body)
+	}
+	return r, nil
+}
+
+// BuildAll build all requests of file, variables referencing response of named request are not supported,
+// use Runner for them
+func (f *File) BuildAll(requester gorequests.Requester, env map[string]string) ([]*gorequests.Request, error) {
+	res := make([]*gorequests.Request, 0, len(f.Requests))
+	for _, v := range f.Requests {
+		r, err := f.Build(requester, v, env, nil)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+type resolver struct {
+	file      *File
+	env       map[string]string
+	responses map[string]*gorequests.Request
+}
+
+func (r *resolver) resolve(s string, depth int) (string, error) {
+	if depth > maxVariableDepth {
+		return "", fmt.Errorf("variable nested too deep in %q", s)
+	}
+	var resolveErr error
+	res := variableRegexp.ReplaceAllStringFunc(s, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		v, err := r.variable(variableRegexp.FindStringSubmatch(match)[1], depth)
+		if err != nil {
+			resolveErr = err
+			return match
+		}
+		return v
+	})
+	return res, resolveErr
+}
+
+func (r *resolver) variable(name string, depth int) (string, error) {
+	if strings.HasPrefix(name, "$") {
+		return systemVariable(name)
+	}
+	if v, ok := r.file.Variables[name]; ok {
+		return r.resolve(v, depth+1)
+	}
+	if v, ok := r.env[name]; ok {
+		return r.resolve(v, depth+1)
+	}
+	if parts := strings.SplitN(name, ".", 4); len(parts) == 4 && parts[1] == "response" {
+		return r.responseVariable(parts[0], parts[2], parts[3])
+	}
+	return "", fmt.Errorf("undefined variable %q", name)
+}
+
+func (r *resolver) responseVariable(name, part, path string) (string, error) {
+	resp, ok := r.responses[name]
+	if !ok {
+		return "", fmt.Errorf("request %q is not sent", name)
+	}
+	switch part {
+	case "body":
+		if path == "*" {
+			return resp.Text()
+		}
+		v, err := resp.JSONPath(path)
+		if err != nil {
+			return "", err
+		}
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		bs, err := json.Marshal(v)
+		return string(bs), err
+	case "headers":
+		return resp.ResponseHeaderByKey(path)
+	default:
+		return "", fmt.Errorf("invalid response variable part %q, should be body or headers", part)
+	}
+}
+
+func systemVariable(name string) (string, error) {
+	fields := strings.Fields(name)
+	switch fields[0] {
+	case "$timestamp":
+		return strconv.FormatInt(time.Now().Unix(), 10), nil
+	case "$guid", "$uuid", "$random.uuid":
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+	case "$randomInt":
+		if len(fields) != 3 {
+			return "", fmt.Errorf("$randomInt need min and max")
+		}
+		min, err1 := strconv.ParseInt(fields[1], 10, 64)
+		max, err2 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || max <= min {
+			return "", fmt.Errorf("invalid $randomInt %q", name)
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(max-min))
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(min+n.Int64(), 10), nil
+	case "$processEnv":
+		if len(fields) != 2 {
+			return "", fmt.Errorf("$processEnv need env name")
+		}
+		return os.Getenv(fields[1]), nil
+	default:
+		return "", fmt.Errorf("unsupported system variable %q", fields[0])
+	}
+}
@@ -0,0 +1,131 @@
+package httpfile_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/jloha/gorequests/httpfile"
+	"github.com/jloha/gorequests/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+const testHTTPFile = `@host = {{baseURL}}/anything
+@user = bob
+
+### login
+POST {{host}}/login HTTP/1.1
+Content-Type: application/json
+
+< ./login.json
+
+###
+# @name profile
+GET {{host}}/users/{{user}}
+    ?page=1
+    &size=10
+Authorization: Bearer {{login.response.body.$.json.token}}
+// comment line
+X-Env: {{env}}
+
+###
+PATCH {{host}}/users/{{user}}
+Content-Type: application/json
+
+{
+  "name": "{{user}}",
+  "auth": "{{profile.response.body.$.headers.Authorization}}"
+}
+
+
+###
+GET {{host}}/missing?v={{undefined}}
+`
+
+func Test_HTTPFile(t *testing.T) {
+	as := assert.New(t)
+	server := testserver.New()
+	defer server.Close()
+
+	dir := t.TempDir()
+	as.Nil(ioutil.WriteFile(filepath.Join(dir, "login.json"), []byte(`{"token": "{{not resolved}}"}`), 0o644))
+	as.Nil(ioutil.WriteFile(filepath.Join(dir, "api.http"), []byte(testHTTPFile), 0o644))
+	as.Nil(ioutil.WriteFile(filepath.Join(dir, "http-client.env.json"), []byte(`{
+		"$shared": {"env": "shared"},
+		"dev": {"baseURL": "`+server.URL+`", "env": "dev"}
+	}`), 0o644))
+
+	file, err := httpfile.ParseFile(filepath.Join(dir, "api.http"))
+	as.Nil(err)
+	as.Len(file.Requests, 4)
+	as.Equal(map[string]string{"host": "{{baseURL}}/anything", "user": "bob"}, file.Variables)
+
+	login, profile, patch := file.Requests[0], file.Requests[1], file.Requests[2]
+	as.Equal("login", login.Name)
+	as.Equal(http.MethodPost, login.Method)
+	as.Equal("{{host}}/login", login.URL)
+	as.Equal("./login.json", login.BodyFile)
+	as.Equal("profile", profile.Name)
+	as.Equal("{{host}}/users/{{user}}?page=1&size=10", profile.URL)
+	as.Equal([][2]string{{"Authorization", "Bearer {{login.response.body.$.json.token}}"}, {"X-Env", "{{env}}"}}, profile.Header)
+	as.Equal("{\n  \"name\": \"{{user}}\",\n  \"auth\": \"{{profile.response.body.$.headers.Authorization}}\"\n}", patch.Body)
+
+	env, err := httpfile.LoadEnv(filepath.Join(dir, "http-client.env.json"), "dev")
+	as.Nil(err)
+	as.Equal("dev", env["env"])
+
+	runner := &httpfile.Runner{
+		Requester: gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger())),
+		Env:       env,
+	}
+	results, err := runner.Run(file)
+	as.NotNil(err)
+	as.Contains(err.Error(), `line 30: undefined variable "undefined"`)
+	as.Len(results, 4)
+
+	as.Nil(results[0].Err)
+	as.Equal(http.StatusOK, results[0].StatusCode)
+	as.Equal("{{not resolved}}", results[0].Response.MustJSONPathString("json.token"))
+
+	as.Nil(results[1].Err)
+	as.Equal("Bearer {{not resolved}}", results[1].Response.MustJSONPathString("headers.Authorization"))
+	as.Equal("dev", results[1].Response.MustJSONPathString("headers.X-Env"))
+	as.Equal("10", results[1].Response.MustJSONPathString("args.size"))
+	as.Equal(server.URL+"/anything/users/bob?page=1&size=10", results[1].Response.MustJSONPathString("url"))
+
+	as.Nil(results[2].Err)
+	as.Equal(http.MethodPatch, results[2].Response.MustJSONPathString("method"))
+	as.Equal("bob", results[2].Response.MustJSONPathString("json.name"))
+	as.Equal("Bearer {{not resolved}}", results[2].Response.MustJSONPathString("json.auth"))
+
+	as.Nil(results[3].Response)
+
+	buf := new(bytes.Buffer)
+	as.Nil(httpfile.Report(buf, results))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	as.Len(lines, 5)
+	as.True(strings.HasPrefix(lines[0], "200    POST    login {{host}}/login"), lines[0])
+	as.True(strings.HasPrefix(lines[3], "ERROR  GET     line 30"), lines[3])
+
+	t.Run("stop on error", func(t *testing.T) {
+		file, err := httpfile.Parse(strings.NewReader("GET {{nope}}\n\n###\nGET "+server.URL+"/get\n"), dir)
+		as.Nil(err)
+		results, err := (&httpfile.Runner{Requester: runner.Requester, StopOnError: true}).Run(file)
+		as.NotNil(err)
+		as.Len(results, 1)
+	})
+
+	t.Run("build all", func(t *testing.T) {
+		file, err := httpfile.Parse(strings.NewReader(server.URL+"/get?a={{a}}\n###\nPOST "+server.URL+"/post\n\nbody {{a}}\n"), dir)
+		as.Nil(err)
+		reqs, err := file.BuildAll(runner.Requester, map[string]string{"a": "1"})
+		as.Nil(err)
+		as.Len(reqs, 2)
+		as.Equal("1", reqs[0].MustJSONPathString("args.a"))
+		as.Equal("body 1", reqs[1].MustJSONPathString("data"))
+	})
+}
@@ -0,0 +1,94 @@
+package httpfile
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jloha/gorequests"
+)
+
+// Runner run requests of .http file in order
+type Runner struct {
+	Requester   gorequests.Requester // create request, e.g. *gorequests.Factory
+	Env         map[string]string    // environment variables, see LoadEnv
+	StopOnError bool                 // stop running when request fail to build or send
+}
+
+// Result is the result of one request
+type Result struct {
+	Request    *Request
+	Response   *gorequests.Request // sent request, nil if fail to build
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// Run run requests of file in order, response of named request can be referenced by following requests,
+// the returned error is the first error of results
+func (r *Runner) Run(f *File) ([]*Result, error) {
+	var (
+		results   = make([]*Result, 0, len(f.Requests))
+		responses = map[string]*gorequests.Request{}
+		firstErr  error
+	)
+	for _, req := range f.Requests {
+		result := r.run(f, req, responses)
+		results = append(results, result)
+		if result.Err != nil {
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+			if r.StopOnError {
+				break
+			}
+		}
+	}
+	return results, firstErr
+}
+
+func (r *Runner) run(f *File, req *Request, responses map[string]*gorequests.Request) *Result {
+	result := &Result{Request: req}
+	resp, err := f.Build(r.Requester, req, r.Env, responses)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	start := time.Now()
+	_, err = resp.Bytes()
+	result.Duration = time.Since(start)
+	result.Response = resp
+	if err != nil {
+		result.Err = fmt.Errorf("httpfile: line %d: %w", req.Line, err)
+		return result
+	}
+	result.StatusCode, _ = resp.ResponseStatus()
+	if req.Name != "" {
+		responses[req.Name] = resp
+	}
+	return result
+}
+
+// Report write results as text table to w
+func Report(w io.Writer, results []*Result) error {
+	for _, v := range results {
+		name := v.Request.Name
+		if name == "" {
+			name = fmt.Sprintf("line %d", v.Request.Line)
+		}
+		status := "ERROR"
+		if v.Err == nil {
+			status = fmt.Sprintf("%d", v.StatusCode)
+		}
+		if _, err := fmt.Fprintf(w, "%-6s %-7s %s %s (%s)\n", status, v.Request.Method, name, v.Request.URL, v.Duration.Round(time.Millisecond)); err != nil {
+			return err
+		}
+		if v.Err != nil {
+			if _, err := fmt.Fprintf(w, "       %s\n", v.Err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
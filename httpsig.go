@@ -0,0 +1,497 @@
+package gorequests
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RFC 9421 signature algorithms
+const (
+	HTTPSigHMACSHA256      = "hmac-sha256"
+	HTTPSigEd25519         = "ed25519"
+	HTTPSigECDSAP256SHA256 = "ecdsa-p256-sha256"
+	HTTPSigECDSAP384SHA384 = "ecdsa-p384-sha384"
+	HTTPSigRSAPSSSHA512    = "rsa-pss-sha512"
+	HTTPSigRSAV15SHA256    = "rsa-v1_5-sha256"
+)
+
+const (
+	defaultHTTPSigLabel     = "sig1"
+	httpSigParamsIdentifier = `"@signature-params"`
+)
+
+// defaultHTTPSigComponents is covered by HTTPMessageSigner if Components is empty
+var defaultHTTPSigComponents = []string{"@method", "@target-uri", "content-digest"}
+
+// HTTPMessageSigner sign request with RFC 9421 HTTP message signatures, Signature-Input and Signature headers are added
+//
+//	signer := &gorequests.HTTPMessageSigner{KeyID: "key-1", Key: ed25519PrivateKey}
+//	fac := gorequests.NewFactory(gorequests.WithSigner(signer))
+//
+// Key is []byte for hmac-sha256, ed25519.PrivateKey, *ecdsa.PrivateKey of P-256 or P-384, or *rsa.PrivateKey,
+// which use rsa-pss-sha512 unless Algorithm is rsa-v1_5-sha256.
+//
+// Components are covered component identifiers, like "@method", "@authority", "content-type" and `@query-param;name="id"`,
+// default is "@method", "@target-uri" and "content-digest",
+// RFC 9530 Content-Digest header with sha-256 of body is added if it's covered and not set.
+type HTTPMessageSigner struct {
+	KeyID      string
+	Key        interface{}
+	Algorithm  string // sent as alg parameter if it's set, otherwise it's inferred from Key
+	Components []string
+	Label      string           // signature label, default is sig1
+	Tag        string           // tag parameter, omitted if empty
+	Expires    time.Duration    // expires parameter is created+Expires, omitted if zero
+	Now        func() time.Time // created parameter, default is time.Now
+	Nonce      func() string    // nonce parameter, omitted if nil
+}
+
+func (s *HTTPMessageSigner) Sign(req *SignRequest) error {
+	components := s.Components
+	if len(components) == 0 {
+		components = defaultHTTPSigComponents
+	}
+	items := make([]httpSigComponent, 0, len(components))
+	for _, v := range components {
+		c, err := parseHTTPSigComponent(v)
+		if err != nil {
+			return err
+		}
+		if c.name == "content-digest" && req.Header.Get("Content-Digest") == "" {
+			req.Header.Set("Content-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sha256Sum(req.Body))+":")
+		}
+		items = append(items, c)
+	}
+
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	params := ";created=" + strconv.FormatInt(now.Unix(), 10)
+	if s.Expires > 0 {
+		params += ";expires=" + strconv.FormatInt(now.Add(s.Expires).Unix(), 10)
+	}
+	if s.KeyID != "" {
+		params += ";keyid=" + quoteSFString(s.KeyID)
+	}
+	if s.Algorithm != "" {
+		params += ";alg=" + quoteSFString(s.Algorithm)
+	}
+	if s.Nonce != nil {
+		params += ";nonce=" + quoteSFString(s.Nonce())
+	}
+	if s.Tag != "" {
+		params += ";tag=" + quoteSFString(s.Tag)
+	}
+	identifiers := make([]string, len(items))
+	for i, c := range items {
+		identifiers[i] = c.identifier
+	}
+	signatureParams := "(" + strings.Join(identifiers, " ") + ")" + params
+
+	base, err := httpSigBase(req, items, signatureParams)
+	if err != nil {
+		return err
+	}
+	signature, err := httpSigSign(s.Key, s.Algorithm, []byte(base))
+	if err != nil {
+		return err
+	}
+	label := s.Label
+	if label == "" {
+		label = defaultHTTPSigLabel
+	}
+	req.Header.Add("Signature-Input", label+"="+signatureParams)
+	req.Header.Add("Signature", label+"=:"+base64.StdEncoding.EncodeToString(signature)+":")
+	return nil
+}
+
+// HTTPMessageVerifier verify RFC 9421 HTTP message signatures of received request, for tests and servers
+type HTTPMessageVerifier struct {
+	// Keys is public key of keyid, []byte for hmac-sha256, ed25519.PublicKey, *ecdsa.PublicKey or *rsa.PublicKey
+	Keys      map[string]interface{}
+	Algorithm string        // used if alg parameter is absent, otherwise it's inferred from key
+	Label     string        // verify signature of label, default is the first signature
+	Required  []string      // component identifiers should be covered, like "@method" and "content-digest"
+	MaxAge    time.Duration // reject signature created before MaxAge, not checked if zero
+}
+
+// Verify check signature of req, and Content-Digest if it's covered,
+// body of req is read and replaced, so it can be read again
+func (v *HTTPMessageVerifier) Verify(req *http.Request) error {
+	inputs := parseSFDictionary(strings.Join(req.Header.Values("Signature-Input"), ", "))
+	signatures := parseSFDictionary(strings.Join(req.Header.Values("Signature"), ", "))
+	label := v.Label
+	if label == "" && len(inputs) > 0 {
+		label = inputs[0][0]
+	}
+	input, signature := sfDictionaryValue(inputs, label), sfDictionaryValue(signatures, label)
+	if input == "" || signature == "" {
+		return fmt.Errorf("%w: signature %q not found", ErrInvalidSignature, label)
+	}
+	if len(signature) < 2 || signature[0] != ':' || signature[len(signature)-1] != ':' {
+		return fmt.Errorf("%w: signature %q is not byte sequence", ErrInvalidSignature, label)
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature[1 : len(signature)-1])
+	if err != nil {
+		return fmt.Errorf("%w: decode signature: %s", ErrInvalidSignature, err)
+	}
+
+	items, params, err := parseHTTPSigInput(input)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	for _, required := range v.Required {
+		c, err := parseHTTPSigComponent(required)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, item := range items {
+			found = found || item.identifier == c.identifier
+		}
+		if !found {
+			return fmt.Errorf("%w: component %s is not covered", ErrInvalidSignature, c.identifier)
+		}
+	}
+	if v.MaxAge > 0 {
+		created, err := strconv.ParseInt(params["created"], 10, 64)
+		if err != nil || time.Since(time.Unix(created, 0)) > v.MaxAge {
+			return fmt.Errorf("%w: signature is expired", ErrInvalidSignature)
+		}
+	}
+	if expires, ok := params["expires"]; ok {
+		if t, err := strconv.ParseInt(expires, 10, 64); err != nil || time.Now().Unix() > t {
+			return fmt.Errorf("%w: signature is expired", ErrInvalidSignature)
+		}
+	}
+	key, ok := v.Keys[params["keyid"]]
+	if !ok {
+		return fmt.Errorf("%w: unknown keyid %q", ErrInvalidSignature, params["keyid"])
+	}
+	alg := params["alg"]
+	if alg == "" {
+		alg = v.Algorithm
+	}
+
+	signReq, err := newSignRequest(req)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.name == "content-digest" {
+			if err := verifyContentDigest(signReq.Header.Get("Content-Digest"), signReq.Body); err != nil {
+				return err
+			}
+		}
+	}
+	base, err := httpSigBase(signReq, items, input)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	if err := httpSigVerify(key, alg, []byte(base), sig); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	return nil
+}
+
+// httpSigComponent is one covered component
+type httpSigComponent struct {
+	identifier string // serialized identifier with parameters, like "@query-param";name="id"
+	name       string // lower case component name
+	param      string // name parameter of @query-param
+}
+
+// parseHTTPSigComponent parse component like @method, content-type or @query-param;name="id", name can be quoted
+func parseHTTPSigComponent(s string) (httpSigComponent, error) {
+	name, params := s, ""
+	if idx := strings.IndexByte(s, ';'); idx >= 0 {
+		name, params = s[:idx], s[idx:]
+	}
+	name = strings.ToLower(strings.Trim(strings.TrimSpace(name), `"`))
+	c := httpSigComponent{identifier: quoteSFString(name) + params, name: name}
+	for _, p := range strings.Split(strings.TrimPrefix(params, ";"), ";") {
+		if k, v, ok := strings.Cut(p, "="); ok && k == "name" {
+			c.param = v
+			if strings.HasPrefix(v, `"`) {
+				c.param, _ = readQuotedString(v)
+			}
+		} else if p != "" {
+			return c, fmt.Errorf("unsupported parameter %q of component %s", p, name)
+		}
+	}
+	if name == "@query-param" && c.param == "" {
+		return c, fmt.Errorf("name parameter of @query-param is required")
+	}
+	return c, nil
+}
+
+// httpSigBase build signature base of RFC 9421 section 2.5
+func httpSigBase(req *SignRequest, items []httpSigComponent, signatureParams string) (string, error) {
+	buf := new(strings.Builder)
+	for _, c := range items {
+		value, err := httpSigComponentValue(req, c)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(c.identifier + ": " + value + "\n")
+	}
+	buf.WriteString(httpSigParamsIdentifier + ": " + signatureParams)
+	return buf.String(), nil
+}
+
+func httpSigComponentValue(req *SignRequest, c httpSigComponent) (string, error) {
+	u := req.URL
+	switch c.name {
+	case "@method":
+		return req.Method, nil
+	case "@target-uri":
+		return u.String(), nil
+	case "@authority":
+		host := strings.ToLower(u.Host)
+		if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+			host = strings.TrimSuffix(host, ":"+port)
+		}
+		return host, nil
+	case "@scheme":
+		return strings.ToLower(u.Scheme), nil
+	case "@request-target":
+		return u.RequestURI(), nil
+	case "@path":
+		if path := u.EscapedPath(); path != "" {
+			return path, nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + u.RawQuery, nil
+	case "@query-param":
+		values, ok := u.Query()[c.param]
+		if !ok || len(values) != 1 {
+			return "", fmt.Errorf("query param %s should have one value", c.param)
+		}
+		return strings.ReplaceAll(url.QueryEscape(values[0]), "+", "%20"), nil
+	}
+	if strings.HasPrefix(c.name, "@") {
+		return "", fmt.Errorf("unsupported component %s", c.name)
+	}
+	values := append([]string(nil), req.Header.Values(c.name)...)
+	if len(values) == 0 {
+		return "", fmt.Errorf("header %s is not found", c.name)
+	}
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return strings.Join(values, ", "), nil
+}
+
+// parseHTTPSigInput parse inner list of Signature-Input member, return components and parameters
+func parseHTTPSigInput(input string) ([]httpSigComponent, map[string]string, error) {
+	end := strings.IndexByte(input, ')')
+	if !strings.HasPrefix(input, "(") || end < 0 {
+		return nil, nil, fmt.Errorf("invalid signature input %s", input)
+	}
+	var items []httpSigComponent
+	for _, v := range strings.Fields(input[1:end]) {
+		c, err := parseHTTPSigComponent(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, c)
+	}
+	params := map[string]string{}
+	for _, p := range strings.Split(strings.TrimPrefix(input[end+1:], ";"), ";") {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			if strings.HasPrefix(v, `"`) {
+				v, _ = readQuotedString(v)
+			}
+			params[k] = v
+		}
+	}
+	return items, params, nil
+}
+
+// parseSFDictionary split structured field dictionary into label and raw value pairs in order
+func parseSFDictionary(s string) [][2]string {
+	var (
+		res     [][2]string
+		start   int
+		depth   int
+		inQuote bool
+	)
+	add := func(member string) {
+		if k, v, ok := strings.Cut(strings.TrimSpace(member), "="); ok {
+			res = append(res, [2]string{strings.TrimSpace(k), strings.TrimSpace(v)})
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case inQuote && c == '\\':
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			add(s[start:i])
+			start = i + 1
+		}
+	}
+	add(s[start:])
+	return res
+}
+
+func sfDictionaryValue(dict [][2]string, label string) string {
+	for _, v := range dict {
+		if v[0] == label {
+			return v[1]
+		}
+	}
+	return ""
+}
+
+func quoteSFString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// verifyContentDigest check sha-256 or sha-512 digest of RFC 9530 Content-Digest header
+func verifyContentDigest(header string, body []byte) error {
+	for _, member := range parseSFDictionary(header) {
+		var sum []byte
+		switch member[0] {
+		case "sha-256":
+			sum = sha256Sum(body)
+		case "sha-512":
+			h := sha512.Sum512(body)
+			sum = h[:]
+		default:
+			continue
+		}
+		if member[1] != ":"+base64.StdEncoding.EncodeToString(sum)+":" {
+			return fmt.Errorf("%w: content digest mismatch", ErrInvalidSignature)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: content digest of sha-256 or sha-512 not found", ErrInvalidSignature)
+}
+
+func sha256Sum(b []byte) []byte {
+	h := sha256.Sum256(b)
+	return h[:]
+}
+
+func httpSigSign(key interface{}, alg string, base []byte) ([]byte, error) {
+	switch key := key.(type) {
+	case []byte:
+		if alg != "" && alg != HTTPSigHMACSHA256 {
+			break
+		}
+		h := hmac.New(sha256.New, key)
+		_, _ = h.Write(base)
+		return h.Sum(nil), nil
+	case ed25519.PrivateKey:
+		if alg != "" && alg != HTTPSigEd25519 {
+			break
+		}
+		return ed25519.Sign(key, base), nil
+	case *ecdsa.PrivateKey:
+		hashed, size, err := httpSigECDSAHash(key.Curve, alg, base)
+		if err != nil {
+			return nil, err
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, key, hashed)
+		if err != nil {
+			return nil, err
+		}
+		sig := make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	case *rsa.PrivateKey:
+		switch alg {
+		case "", HTTPSigRSAPSSSHA512:
+			h := sha512.Sum512(base)
+			return rsa.SignPSS(rand.Reader, key, crypto.SHA512, h[:], &rsa.PSSOptions{SaltLength: 64})
+		case HTTPSigRSAV15SHA256:
+			h := sha256.Sum256(base)
+			return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing key %T", key)
+	}
+	return nil, fmt.Errorf("algorithm %q does not match key %T", alg, key)
+}
+
+func httpSigVerify(key interface{}, alg string, base, sig []byte) error {
+	var ok bool
+	switch key := key.(type) {
+	case []byte:
+		if alg != "" && alg != HTTPSigHMACSHA256 {
+			return fmt.Errorf("algorithm %q does not match key %T", alg, key)
+		}
+		h := hmac.New(sha256.New, key)
+		_, _ = h.Write(base)
+		ok = hmac.Equal(h.Sum(nil), sig)
+	case ed25519.PublicKey:
+		if alg != "" && alg != HTTPSigEd25519 {
+			return fmt.Errorf("algorithm %q does not match key %T", alg, key)
+		}
+		ok = ed25519.Verify(key, base, sig)
+	case *ecdsa.PublicKey:
+		hashed, size, err := httpSigECDSAHash(key.Curve, alg, base)
+		if err != nil {
+			return err
+		}
+		if len(sig) != 2*size {
+			return fmt.Errorf("signature length %d is invalid", len(sig))
+		}
+		ok = ecdsa.Verify(key, hashed, new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:]))
+	case *rsa.PublicKey:
+		switch alg {
+		case "", HTTPSigRSAPSSSHA512:
+			h := sha512.Sum512(base)
+			ok = rsa.VerifyPSS(key, crypto.SHA512, h[:], sig, &rsa.PSSOptions{SaltLength: 64}) == nil
+		case HTTPSigRSAV15SHA256:
+			h := sha256.Sum256(base)
+			ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], sig) == nil
+		default:
+			return fmt.Errorf("algorithm %q does not match key %T", alg, key)
+		}
+	default:
+		return fmt.Errorf("unsupported verifying key %T", key)
+	}
+	if !ok {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// httpSigECDSAHash return hash of base and size of r and s of curve
+func httpSigECDSAHash(curve elliptic.Curve, alg string, base []byte) ([]byte, int, error) {
+	switch {
+	case curve == elliptic.P256() && (alg == "" || alg == HTTPSigECDSAP256SHA256):
+		h := sha256.Sum256(base)
+		return h[:], 32, nil
+	case curve == elliptic.P384() && (alg == "" || alg == HTTPSigECDSAP384SHA384):
+		h := sha512.Sum384(base)
+		return h[:], 48, nil
+	}
+	return nil, 0, fmt.Errorf("algorithm %q does not match curve %s", alg, curve.Params().Name)
+}
@@ -0,0 +1,248 @@
+package gorequests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrJSONPathNotFound     = errors.New("json path not found")
+	ErrJSONPathTypeMismatch = errors.New("json path type mismatch")
+)
+
+// JSONPath send request and return value of path in json response body,
+// path is dot separated keys and array indexes, like: data.items.0.id or data.items[0].id,
+// empty path return the whole body.
+//
+// json is parsed once from the cached response body, object is map[string]interface{},
+// array is []interface{}, number is json.Number.
+func (r *Request) JSONPath(path string) (interface{}, error) {
+	root, err := r.jsonRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, r.newError(KindInvalidRequest, "json path "+path, err)
+	}
+	cur := root
+	for i, seg := range segments {
+		at := strings.Join(segments[:i], ".")
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			val, ok := v[seg]
+			if !ok {
+				return nil, r.newJSONPathError(path, fmt.Errorf("%w: key %q not exist at %q", ErrJSONPathNotFound, seg, at))
+			}
+			cur = val
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil {
+				return nil, r.newJSONPathError(path, fmt.Errorf("%w: %q is not index of array at %q", ErrJSONPathTypeMismatch, seg, at))
+			}
+			if idx < 0 || idx >= len(v) {
+				return nil, r.newJSONPathError(path, fmt.Errorf("%w: index %d out of range at %q, length is %d", ErrJSONPathNotFound, idx, at, len(v)))
+			}
+			cur = v[idx]
+		default:
+			return nil, r.newJSONPathError(path, fmt.Errorf("%w: %s at %q is not object or array", ErrJSONPathTypeMismatch, jsonTypeName(cur), at))
+		}
+	}
+	return cur, nil
+}
+
+func (r *Request) MustJSONPath(path string) interface{} {
+	val, _ := r.JSONPath(path)
+	return val
+}
+
+// JSONPathString return string value of path, see JSONPath
+func (r *Request) JSONPathString(path string) (string, error) {
+	val, err := r.JSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	s, ok := val.(string)
+	if !ok {
+		return "", r.newJSONPathTypeError(path, "string", val)
+	}
+	return s, nil
+}
+
+func (r *Request) MustJSONPathString(path string) string {
+	val, _ := r.JSONPathString(path)
+	return val
+}
+
+// JSONPathInt return integer value of path, see JSONPath
+func (r *Request) JSONPathInt(path string) (int64, error) {
+	val, err := r.JSONPath(path)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := val.(json.Number)
+	if !ok {
+		return 0, r.newJSONPathTypeError(path, "integer", val)
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return 0, r.newJSONPathTypeError(path, "integer", val)
+	}
+	return i, nil
+}
+
+func (r *Request) MustJSONPathInt(path string) int64 {
+	val, _ := r.JSONPathInt(path)
+	return val
+}
+
+// JSONPathFloat return number value of path, see JSONPath
+func (r *Request) JSONPathFloat(path string) (float64, error) {
+	val, err := r.JSONPath(path)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := val.(json.Number)
+	if !ok {
+		return 0, r.newJSONPathTypeError(path, "number", val)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, r.newJSONPathTypeError(path, "number", val)
+	}
+	return f, nil
+}
+
+func (r *Request) MustJSONPathFloat(path string) float64 {
+	val, _ := r.JSONPathFloat(path)
+	return val
+}
+
+// JSONPathBool return bool value of path, see JSONPath
+func (r *Request) JSONPathBool(path string) (bool, error) {
+	val, err := r.JSONPath(path)
+	if err != nil {
+		return false, err
+	}
+	b, ok := val.(bool)
+	if !ok {
+		return false, r.newJSONPathTypeError(path, "boolean", val)
+	}
+	return b, nil
+}
+
+func (r *Request) MustJSONPathBool(path string) bool {
+	val, _ := r.JSONPathBool(path)
+	return val
+}
+
+// JSONPathArray return array value of path, see JSONPath
+func (r *Request) JSONPathArray(path string) ([]interface{}, error) {
+	val, err := r.JSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := val.([]interface{})
+	if !ok {
+		return nil, r.newJSONPathTypeError(path, "array", val)
+	}
+	return arr, nil
+}
+
+func (r *Request) MustJSONPathArray(path string) []interface{} {
+	val, _ := r.JSONPathArray(path)
+	return val
+}
+
+// jsonRoot parse response body once
+func (r *Request) jsonRoot() (interface{}, error) {
+	bs, err := r.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.isJSONParsed {
+		dec := json.NewDecoder(bytes.NewReader(bs))
+		dec.UseNumber()
+		if err := dec.Decode(&r.jsonValue); err != nil {
+			r.jsonErr = r.newError(KindDecode, "unmarshal json", decodeError(err, bs))
+		}
+		r.isJSONParsed = true
+	}
+	return r.jsonValue, r.jsonErr
+}
+
+func (r *Request) newJSONPathError(path string, err error) error {
+	return r.newError(KindDecode, "json path "+path, err)
+}
+
+func (r *Request) newJSONPathTypeError(path, expect string, val interface{}) error {
+	return r.newJSONPathError(path, fmt.Errorf("%w: expect %s, but got %s", ErrJSONPathTypeMismatch, expect, jsonTypeName(val)))
+}
+
+// parseJSONPath split a.b[0].c to [a b 0 c]
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	segments := []string{}
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		idx := strings.IndexByte(part, '[')
+		if idx >= 0 {
+			key = part[:idx]
+		}
+		if key != "" {
+			segments = append(segments, key)
+		} else if idx != 0 {
+			return nil, fmt.Errorf("invalid json path %q: empty key", path)
+		}
+		for idx >= 0 {
+			end := strings.IndexByte(part[idx:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: missing ]", path)
+			}
+			segments = append(segments, part[idx+1:idx+end])
+			part = part[idx+end+1:]
+			if part == "" {
+				break
+			}
+			if part[0] != '[' {
+				return nil, fmt.Errorf("invalid json path %q: unexpected %q", path, part)
+			}
+			idx = 0
+		}
+	}
+	return segments, nil
+}
+
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	if _, ok := jsonNumber(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,91 @@
+package gorequests_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JSONPath(t *testing.T) {
+	as := assert.New(t)
+
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		_, _ = w.Write([]byte(`{"data":{"total":9007199254740993,"ratio":0.5,"ok":true,"name":"n","items":[{"id":"a"},{"id":"b"}],"null":null}}`))
+	}))
+	defer server.Close()
+
+	r := gorequests.New(http.MethodGet, server.URL)
+
+	t.Run("typed", func(t *testing.T) {
+		s, err := r.JSONPathString("data.items.1.id")
+		as.Nil(err)
+		as.Equal("b", s)
+
+		s, err = r.JSONPathString("data.items[0].id")
+		as.Nil(err)
+		as.Equal("a", s)
+
+		i, err := r.JSONPathInt("data.total")
+		as.Nil(err)
+		as.Equal(int64(9007199254740993), i)
+
+		f, err := r.JSONPathFloat("$.data.ratio")
+		as.Nil(err)
+		as.Equal(0.5, f)
+
+		b, err := r.JSONPathBool("data.ok")
+		as.Nil(err)
+		as.True(b)
+
+		arr, err := r.JSONPathArray("data.items")
+		as.Nil(err)
+		as.Len(arr, 2)
+
+		v, err := r.JSONPath("data.null")
+		as.Nil(err)
+		as.Nil(v)
+
+		root, err := r.JSONPath("")
+		as.Nil(err)
+		as.Contains(root, "data")
+
+		as.Equal("n", r.MustJSONPathString("data.name"))
+		as.Equal(1, count)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := r.JSONPathString("data.missing")
+		as.True(errors.Is(err, gorequests.ErrJSONPathNotFound))
+		as.True(errors.Is(err, gorequests.ErrDecode))
+		as.Contains(err.Error(), `key "missing" not exist at "data"`)
+
+		_, err = r.JSONPath("data.items.2.id")
+		as.True(errors.Is(err, gorequests.ErrJSONPathNotFound))
+		as.Contains(err.Error(), "index 2 out of range")
+
+		_, err = r.JSONPathInt("data.ratio")
+		as.True(errors.Is(err, gorequests.ErrJSONPathTypeMismatch))
+
+		_, err = r.JSONPathString("data.total")
+		as.True(errors.Is(err, gorequests.ErrJSONPathTypeMismatch))
+		as.Contains(err.Error(), "expect string, but got number")
+
+		_, err = r.JSONPath("data.name.x")
+		as.True(errors.Is(err, gorequests.ErrJSONPathTypeMismatch))
+
+		_, err = r.JSONPath("data.items.x")
+		as.True(errors.Is(err, gorequests.ErrJSONPathTypeMismatch))
+
+		_, err = r.JSONPath("data.items[0")
+		as.True(errors.Is(err, gorequests.ErrInvalidRequest))
+
+		_, err = gorequests.New(http.MethodGet, server.URL).WithExpectStatus(201).JSONPath("data")
+		as.True(errors.Is(err, gorequests.ErrUnexpectedStatus))
+	})
+}
@@ -0,0 +1,749 @@
+package gorequests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaMode control what happens when response violates json schema
+type SchemaMode int
+
+const (
+	SchemaModeEnforce SchemaMode = iota // Unmarshal return *SchemaError
+	SchemaModeLogOnly                   // violations are logged by Logger, Unmarshal succeed
+)
+
+// SchemaViolation is one violation of json schema
+type SchemaViolation struct {
+	Pointer string // JSON pointer of the violated value, like: /data/items/0/id, empty for root
+	Keyword string // violated keyword, like: type, required
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	return fmt.Sprintf("%s: %s: %s", pointerOrRoot(v.Pointer), v.Keyword, v.Message)
+}
+
+// SchemaError is returned by Unmarshal when response violates json schema, see WithJSONSchema
+type SchemaError struct {
+	Method     string
+	URL        string
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.String())
+	}
+	return fmt.Sprintf("gorequests: %s %s response schema validation failed: %s", e.Method, e.URL, strings.Join(msgs, "; "))
+}
+
+// Is match ErrSchemaViolation
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrSchemaViolation
+}
+
+// JSONSchema is a compiled JSON Schema, support subset of draft 2020-12:
+//
+//	type, enum, const,
+//	properties, patternProperties, additionalProperties, required, minProperties, maxProperties,
+//	prefixItems, items, contains, minItems, maxItems, uniqueItems,
+//	minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf,
+//	minLength, maxLength, pattern,
+//	allOf, anyOf, oneOf, not, if, then, else,
+//	$defs and local $ref like "#/$defs/item".
+//
+// other keywords, like format, are ignored.
+type JSONSchema struct {
+	root *schemaNode
+}
+
+// CompileJSONSchema compile json schema
+func CompileJSONSchema(schema []byte) (*JSONSchema, error) {
+	doc, err := decodeJSONUseNumber(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+	c := &schemaCompiler{doc: doc, refs: map[string]*schemaNode{}}
+	root, err := c.compile(doc, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+	return &JSONSchema{root: root}, nil
+}
+
+// MustCompileJSONSchema compile json schema, panic if schema is invalid
+func MustCompileJSONSchema(schema []byte) *JSONSchema {
+	s, err := CompileJSONSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Validate validate decoded json value, return nil if valid
+func (s *JSONSchema) Validate(val interface{}) []SchemaViolation {
+	var violations []SchemaViolation
+	s.root.validate(val, "", &violations)
+	return violations
+}
+
+// ValidateJSON validate json bytes, return nil if valid
+func (s *JSONSchema) ValidateJSON(bs []byte) ([]SchemaViolation, error) {
+	val, err := decodeJSONUseNumber(bs)
+	if err != nil {
+		return nil, err
+	}
+	return s.Validate(val), nil
+}
+
+func (r *Request) validateJSONSchema(bs []byte) error {
+	if r.jsonSchema == nil {
+		return nil
+	}
+	violations, err := r.jsonSchema.ValidateJSON(bs)
+	if err != nil || len(violations) == 0 {
+		return nil // invalid json is reported by unmarshal
+	}
+
+	schemaErr := &SchemaError{Method: r.method, URL: r.cachedurl, Violations: violations}
+	if r.jsonSchemaMode == SchemaModeLogOnly {
+		r.logger.Error(r.Context(), "%s", schemaErr)
+		return nil
+	}
+	return schemaErr
+}
+
+type schemaNode struct {
+	boolean *bool
+
+	types    []string
+	enum     []interface{}
+	hasConst bool
+	constVal interface{}
+
+	properties           map[string]*schemaNode
+	patternProperties    []patternSchema
+	additionalProperties *schemaNode
+	required             []string
+	minProperties        *int
+	maxProperties        *int
+
+	prefixItems []*schemaNode
+	items       *schemaNode
+	contains    *schemaNode
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	allOf []*schemaNode
+	anyOf []*schemaNode
+	oneOf []*schemaNode
+	not   *schemaNode
+	ifS   *schemaNode
+	thenS *schemaNode
+	elseS *schemaNode
+	ref   *schemaNode
+}
+
+type patternSchema struct {
+	pattern *regexp.Regexp
+	schema  *schemaNode
+}
+
+type schemaCompiler struct {
+	doc  interface{}
+	refs map[string]*schemaNode
+}
+
+func (c *schemaCompiler) compile(v interface{}, at string) (*schemaNode, error) {
+	if b, ok := v.(bool); ok {
+		return &schemaNode{boolean: &b}, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema at %s must be object or boolean", pointerOrRoot(at))
+	}
+
+	n := &schemaNode{}
+	var err error
+	for key, val := range m {
+		kat := at + "/" + escapeJSONPointer(key)
+		switch key {
+		case "type":
+			switch t := val.(type) {
+			case string:
+				n.types = []string{t}
+			case []interface{}:
+				for _, item := range t {
+					s, ok := item.(string)
+					if !ok {
+						return nil, fmt.Errorf("%s must be string or array of string", kat)
+					}
+					n.types = append(n.types, s)
+				}
+			default:
+				return nil, fmt.Errorf("%s must be string or array of string", kat)
+			}
+		case "enum":
+			arr, ok := val.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s must be array", kat)
+			}
+			n.enum = arr
+		case "const":
+			n.hasConst, n.constVal = true, val
+		case "properties":
+			props, ok := val.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s must be object", kat)
+			}
+			n.properties = map[string]*schemaNode{}
+			for name, sub := range props {
+				if n.properties[name], err = c.compile(sub, kat+"/"+escapeJSONPointer(name)); err != nil {
+					return nil, err
+				}
+			}
+		case "patternProperties":
+			props, ok := val.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s must be object", kat)
+			}
+			for pattern, sub := range props {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", kat, err)
+				}
+				s, err := c.compile(sub, kat+"/"+escapeJSONPointer(pattern))
+				if err != nil {
+					return nil, err
+				}
+				n.patternProperties = append(n.patternProperties, patternSchema{pattern: re, schema: s})
+			}
+		case "additionalProperties":
+			if n.additionalProperties, err = c.compile(val, kat); err != nil {
+				return nil, err
+			}
+		case "required":
+			arr, ok := val.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s must be array", kat)
+			}
+			for _, item := range arr {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s must be array of string", kat)
+				}
+				n.required = append(n.required, s)
+			}
+		case "prefixItems":
+			if n.prefixItems, err = c.compileList(val, kat); err != nil {
+				return nil, err
+			}
+		case "items":
+			if n.items, err = c.compile(val, kat); err != nil {
+				return nil, err
+			}
+		case "contains":
+			if n.contains, err = c.compile(val, kat); err != nil {
+				return nil, err
+			}
+		case "uniqueItems":
+			n.uniqueItems, _ = val.(bool)
+		case "minProperties", "maxProperties", "minItems", "maxItems", "minLength", "maxLength":
+			i, err := schemaInt(val, kat)
+			if err != nil {
+				return nil, err
+			}
+			switch key {
+			case "minProperties":
+				n.minProperties = &i
+			case "maxProperties":
+				n.maxProperties = &i
+			case "minItems":
+				n.minItems = &i
+			case "maxItems":
+				n.maxItems = &i
+			case "minLength":
+				n.minLength = &i
+			case "maxLength":
+				n.maxLength = &i
+			}
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			f, ok := jsonNumber(val)
+			if !ok {
+				return nil, fmt.Errorf("%s must be number", kat)
+			}
+			switch key {
+			case "minimum":
+				n.minimum = &f
+			case "maximum":
+				n.maximum = &f
+			case "exclusiveMinimum":
+				n.exclusiveMinimum = &f
+			case "exclusiveMaximum":
+				n.exclusiveMaximum = &f
+			case "multipleOf":
+				if f <= 0 {
+					return nil, fmt.Errorf("%s must be greater than 0", kat)
+				}
+				n.multipleOf = &f
+			}
+		case "pattern":
+			s, ok := val.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be string", kat)
+			}
+			if n.pattern, err = regexp.Compile(s); err != nil {
+				return nil, fmt.Errorf("%s: %w", kat, err)
+			}
+		case "allOf":
+			if n.allOf, err = c.compileList(val, kat); err != nil {
+				return nil, err
+			}
+		case "anyOf":
+			if n.anyOf, err = c.compileList(val, kat); err != nil {
+				return nil, err
+			}
+		case "oneOf":
+			if n.oneOf, err = c.compileList(val, kat); err != nil {
+				return nil, err
+			}
+		case "not":
+			if n.not, err = c.compile(val, kat); err != nil {
+				return nil, err
+			}
+		case "if":
+			if n.ifS, err = c.compile(val, kat); err != nil {
+				return nil, err
+			}
+		case "then":
+			if n.thenS, err = c.compile(val, kat); err != nil {
+				return nil, err
+			}
+		case "else":
+			if n.elseS, err = c.compile(val, kat); err != nil {
+				return nil, err
+			}
+		case "$ref":
+			ref, ok := val.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be string", kat)
+			}
+			if n.ref, err = c.resolveRef(ref); err != nil {
+				return nil, fmt.Errorf("%s: %w", kat, err)
+			}
+		}
+	}
+	return n, nil
+}
+
+func (c *schemaCompiler) compileList(v interface{}, at string) ([]*schemaNode, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be array", at)
+	}
+	nodes := make([]*schemaNode, 0, len(arr))
+	for i, item := range arr {
+		n, err := c.compile(item, at+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// resolveRef resolve local ref like "#/$defs/item", compiled node is shared for recursive schema
+func (c *schemaCompiler) resolveRef(ref string) (*schemaNode, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q, only local ref is supported", ref)
+	}
+	pointer, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+	if n, ok := c.refs[pointer]; ok {
+		return n, nil
+	}
+
+	target := c.doc
+	if pointer != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			switch t := target.(type) {
+			case map[string]interface{}:
+				var ok bool
+				if target, ok = t[token]; !ok {
+					return nil, fmt.Errorf("$ref %q not found", ref)
+				}
+			case []interface{}:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || i >= len(t) {
+					return nil, fmt.Errorf("$ref %q not found", ref)
+				}
+				target = t[i]
+			default:
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+		}
+	}
+
+	// register before compile, so recursive ref get the same node
+	n := &schemaNode{}
+	c.refs[pointer] = n
+	compiled, err := c.compile(target, pointer)
+	if err != nil {
+		return nil, err
+	}
+	*n = *compiled
+	return n, nil
+}
+
+func (n *schemaNode) validate(v interface{}, at string, out *[]SchemaViolation) {
+	add := func(keyword, format string, args ...interface{}) {
+		*out = append(*out, SchemaViolation{Pointer: at, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.boolean != nil {
+		if !*n.boolean {
+			add("false", "no value is allowed")
+		}
+		return
+	}
+	if n.ref != nil {
+		n.ref.validate(v, at, out)
+	}
+
+	if len(n.types) > 0 {
+		matched := false
+		for _, t := range n.types {
+			if isJSONType(v, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("type", "expected %s, but got %s", strings.Join(n.types, " or "), jsonTypeName(v))
+			return
+		}
+	}
+	if n.enum != nil {
+		matched := false
+		for _, item := range n.enum {
+			if jsonEqual(v, item) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("enum", "value %s is not one of enum", jsonString(v))
+		}
+	}
+	if n.hasConst && !jsonEqual(v, n.constVal) {
+		add("const", "value %s is not %s", jsonString(v), jsonString(n.constVal))
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		n.validateObject(val, at, out, add)
+	case []interface{}:
+		n.validateArray(val, at, out, add)
+	case string:
+		length := utf8.RuneCountInString(val)
+		if n.minLength != nil && length < *n.minLength {
+			add("minLength", "length %d is less than %d", length, *n.minLength)
+		}
+		if n.maxLength != nil && length > *n.maxLength {
+			add("maxLength", "length %d is greater than %d", length, *n.maxLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(val) {
+			add("pattern", "%q does not match pattern %q", val, n.pattern.String())
+		}
+	default:
+		if f, ok := jsonNumber(v); ok {
+			n.validateNumber(f, add)
+		}
+	}
+
+	for _, sub := range n.allOf {
+		sub.validate(v, at, out)
+	}
+	if len(n.anyOf) > 0 {
+		matched := false
+		for _, sub := range n.anyOf {
+			if sub.isValid(v) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("anyOf", "value does not match any schema")
+		}
+	}
+	if len(n.oneOf) > 0 {
+		count := 0
+		for _, sub := range n.oneOf {
+			if sub.isValid(v) {
+				count++
+			}
+		}
+		if count != 1 {
+			add("oneOf", "value match %d schemas, expected exactly 1", count)
+		}
+	}
+	if n.not != nil && n.not.isValid(v) {
+		add("not", "value should not match schema")
+	}
+	if n.ifS != nil {
+		if n.ifS.isValid(v) {
+			if n.thenS != nil {
+				n.thenS.validate(v, at, out)
+			}
+		} else if n.elseS != nil {
+			n.elseS.validate(v, at, out)
+		}
+	}
+}
+
+func (n *schemaNode) validateObject(val map[string]interface{}, at string, out *[]SchemaViolation, add func(keyword, format string, args ...interface{})) {
+	for _, name := range n.required {
+		if _, ok := val[name]; !ok {
+			add("required", "missing required property %q", name)
+		}
+	}
+	if n.minProperties != nil && len(val) < *n.minProperties {
+		add("minProperties", "has %d properties, less than %d", len(val), *n.minProperties)
+	}
+	if n.maxProperties != nil && len(val) > *n.maxProperties {
+		add("maxProperties", "has %d properties, greater than %d", len(val), *n.maxProperties)
+	}
+
+	keys := make([]string, 0, len(val))
+	for k := range val {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		kat := at + "/" + escapeJSONPointer(k)
+		evaluated := false
+		if sub, ok := n.properties[k]; ok {
+			sub.validate(val[k], kat, out)
+			evaluated = true
+		}
+		for _, p := range n.patternProperties {
+			if p.pattern.MatchString(k) {
+				p.schema.validate(val[k], kat, out)
+				evaluated = true
+			}
+		}
+		if !evaluated && n.additionalProperties != nil {
+			if n.additionalProperties.boolean != nil && !*n.additionalProperties.boolean {
+				*out = append(*out, SchemaViolation{Pointer: kat, Keyword: "additionalProperties", Message: fmt.Sprintf("property %q is not allowed", k)})
+				continue
+			}
+			n.additionalProperties.validate(val[k], kat, out)
+		}
+	}
+}
+
+func (n *schemaNode) validateArray(val []interface{}, at string, out *[]SchemaViolation, add func(keyword, format string, args ...interface{})) {
+	if n.minItems != nil && len(val) < *n.minItems {
+		add("minItems", "has %d items, less than %d", len(val), *n.minItems)
+	}
+	if n.maxItems != nil && len(val) > *n.maxItems {
+		add("maxItems", "has %d items, greater than %d", len(val), *n.maxItems)
+	}
+	for i, item := range val {
+		iat := at + "/" + strconv.Itoa(i)
+		if i < len(n.prefixItems) {
+			n.prefixItems[i].validate(item, iat, out)
+		} else if n.items != nil {
+			n.items.validate(item, iat, out)
+		}
+	}
+	if n.contains != nil {
+		matched := false
+		for _, item := range val {
+			if n.contains.isValid(item) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("contains", "no item match contains schema")
+		}
+	}
+	if n.uniqueItems {
+		for i := 0; i < len(val); i++ {
+			for j := i + 1; j < len(val); j++ {
+				if jsonEqual(val[i], val[j]) {
+					add("uniqueItems", "item %d and %d are equal", i, j)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (n *schemaNode) validateNumber(f float64, add func(keyword, format string, args ...interface{})) {
+	if n.minimum != nil && f < *n.minimum {
+		add("minimum", "%v is less than %v", f, *n.minimum)
+	}
+	if n.maximum != nil && f > *n.maximum {
+		add("maximum", "%v is greater than %v", f, *n.maximum)
+	}
+	if n.exclusiveMinimum != nil && f <= *n.exclusiveMinimum {
+		add("exclusiveMinimum", "%v is less than or equal to %v", f, *n.exclusiveMinimum)
+	}
+	if n.exclusiveMaximum != nil && f >= *n.exclusiveMaximum {
+		add("exclusiveMaximum", "%v is greater than or equal to %v", f, *n.exclusiveMaximum)
+	}
+	if n.multipleOf != nil {
+		q := f / *n.multipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			add("multipleOf", "%v is not multiple of %v", f, *n.multipleOf)
+		}
+	}
+}
+
+func (n *schemaNode) isValid(v interface{}) bool {
+	var violations []SchemaViolation
+	n.validate(v, "", &violations)
+	return len(violations) == 0
+}
+
+func isJSONType(v interface{}, typ string) bool {
+	switch typ {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := jsonNumber(v)
+		return ok
+	case "integer":
+		f, ok := jsonNumber(v)
+		return ok && f == math.Trunc(f)
+	}
+	return false
+}
+
+// jsonNumber convert json.Number and go number to float64
+func jsonNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func jsonEqual(a, b interface{}) bool {
+	if fa, ok := jsonNumber(a); ok {
+		fb, ok := jsonNumber(b)
+		return ok && fa == fb
+	}
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			if w, ok := bv[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func jsonString(v interface{}) string {
+	bs, _ := json.Marshal(v)
+	return string(bs)
+}
+
+func schemaInt(v interface{}, at string) (int, error) {
+	f, ok := jsonNumber(v)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return 0, fmt.Errorf("%s must be non-negative integer", at)
+	}
+	return int(f), nil
+}
+
+func decodeJSONUseNumber(bs []byte) (interface{}, error) {
+	var val interface{}
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func escapeJSONPointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func pointerOrRoot(pointer string) string {
+	if pointer == "" {
+		return "(root)"
+	}
+	return pointer
+}
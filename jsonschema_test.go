@@ -0,0 +1,142 @@
+package gorequests_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecordLogger struct {
+	infos  []string
+	errors []string
+}
+
+func (r *testRecordLogger) Info(ctx context.Context, format string, v ...interface{}) {
+	r.infos = append(r.infos, fmt.Sprintf(format, v...))
+}
+
+func (r *testRecordLogger) Error(ctx context.Context, format string, v ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, v...))
+}
+
+var testUserSchema = gorequests.MustCompileJSONSchema([]byte(`{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["id", "name"],
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"name": {"type": "string", "minLength": 1},
+		"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}}
+	},
+	"$defs": {
+		"tag": {"type": "string", "enum": ["a", "b"]}
+	}
+}`))
+
+func Test_JSONSchema(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/1":
+			_, _ = w.Write([]byte(`{"id":1,"name":"a","tags":["a"]}`))
+		case "/users/2":
+			_, _ = w.Write([]byte(`{"id":"2","tags":["a","c"]}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Run("enforce", func(t *testing.T) {
+		user := testUser{}
+		as.Nil(gorequests.New(http.MethodGet, server.URL+"/users/1").WithJSONSchema(testUserSchema, gorequests.SchemaModeEnforce).Unmarshal(&user))
+		as.Equal(1, user.ID)
+
+		err := gorequests.New(http.MethodGet, server.URL+"/users/2").WithJSONSchema(testUserSchema, gorequests.SchemaModeEnforce).Unmarshal(&user)
+		as.True(errors.Is(err, gorequests.ErrSchemaViolation))
+		as.Equal(gorequests.KindSchema, gorequests.KindOf(err))
+		var schemaErr *gorequests.SchemaError
+		as.True(errors.As(err, &schemaErr))
+		as.Equal([]gorequests.SchemaViolation{
+			{Pointer: "", Keyword: "required", Message: `missing required property "name"`},
+			{Pointer: "/id", Keyword: "type", Message: "expected integer, but got string"},
+			{Pointer: "/tags/1", Keyword: "enum", Message: `value "c" is not one of enum`},
+		}, schemaErr.Violations)
+	})
+
+	t.Run("log only", func(t *testing.T) {
+		logger := &testRecordLogger{}
+		user := testUser{}
+		err := gorequests.New(http.MethodGet, server.URL+"/users/2").WithLogger(logger).WithJSONSchema(testUserSchema, gorequests.SchemaModeLogOnly).Unmarshal(&user)
+		as.NotNil(err) // "2" can not unmarshal to int
+		as.True(errors.Is(err, gorequests.ErrDecode))
+		as.Len(logger.errors, 1)
+		as.Contains(logger.errors[0], "/tags/1: enum")
+	})
+
+	t.Run("route", func(t *testing.T) {
+		fac := gorequests.NewFactory(
+			gorequests.WithBaseURL(server.URL),
+			gorequests.WithRouteJSONSchema(http.MethodGet, "/users/{id}", testUserSchema, gorequests.SchemaModeEnforce),
+		)
+		err := fac.New(http.MethodGet, "/users/{id}").WithPathParam("id", "2").Unmarshal(&testUser{})
+		as.True(errors.Is(err, gorequests.ErrSchemaViolation))
+
+		// not match route
+		m := map[string]interface{}{}
+		as.Nil(fac.New(http.MethodGet, "/users/2").Unmarshal(&m))
+	})
+}
+
+func Test_JSONSchemaKeywords(t *testing.T) {
+	as := assert.New(t)
+
+	validate := func(schema, val string) []string {
+		s, err := gorequests.CompileJSONSchema([]byte(schema))
+		as.Nil(err)
+		violations, err := s.ValidateJSON([]byte(val))
+		as.Nil(err)
+		res := []string{}
+		for _, v := range violations {
+			res = append(res, v.Pointer+" "+v.Keyword)
+		}
+		return res
+	}
+
+	as.Equal([]string{}, validate(`{"type":["string","null"]}`, `null`))
+	as.Equal([]string{" type"}, validate(`{"type":"integer"}`, `1.5`))
+	as.Equal([]string{}, validate(`{"type":"integer"}`, `2.0`))
+	as.Equal([]string{" const"}, validate(`{"const":{"a":[1]}}`, `{"a":[2]}`))
+	as.Equal([]string{" minimum", " multipleOf"}, validate(`{"minimum":5,"multipleOf":2}`, `3`))
+	as.Equal([]string{" exclusiveMaximum"}, validate(`{"exclusiveMaximum":3}`, `3`))
+	as.Equal([]string{" maxLength", " pattern"}, validate(`{"maxLength":2,"pattern":"^a"}`, `"bcd"`))
+	as.Equal([]string{"/x additionalProperties"}, validate(`{"properties":{"a":true},"additionalProperties":false}`, `{"a":1,"x":2}`))
+	as.Equal([]string{"/s_1 type"}, validate(`{"patternProperties":{"^s_":{"type":"string"}}}`, `{"s_1":1}`))
+	as.Equal([]string{" maxProperties"}, validate(`{"maxProperties":1}`, `{"a":1,"b":2}`))
+	as.Equal([]string{" minItems", "/1 type"}, validate(`{"prefixItems":[{"type":"string"}],"items":{"type":"integer"},"minItems":3}`, `["a","b"]`))
+	as.Equal([]string{" uniqueItems"}, validate(`{"uniqueItems":true}`, `[1,{"a":1},{"a":1.0}]`))
+	as.Equal([]string{" contains"}, validate(`{"contains":{"type":"string"}}`, `[1,2]`))
+	as.Equal([]string{" anyOf"}, validate(`{"anyOf":[{"type":"string"},{"minimum":10}]}`, `1`))
+	as.Equal([]string{" oneOf"}, validate(`{"oneOf":[{"type":"integer"},{"minimum":0}]}`, `1`))
+	as.Equal([]string{" not"}, validate(`{"not":{"type":"null"}}`, `null`))
+	as.Equal([]string{"/a minimum", " type"}, validate(`{"allOf":[{"type":"object"},{"type":"array"}],"properties":{"a":{"minimum":1}}}`, `{"a":0}`))
+	as.Equal([]string{" required"}, validate(`{"if":{"properties":{"t":{"const":"x"}}},"then":{"required":["x"]},"else":{"required":["y"]}}`, `{"t":"x"}`))
+	as.Equal([]string{"/next/next/v type"}, validate(`{"$ref":"#/$defs/node","$defs":{"node":{"properties":{"v":{"type":"integer"},"next":{"$ref":"#/$defs/node"}}}}}`, `{"v":1,"next":{"v":2,"next":{"v":"3"}}}`))
+	as.Equal([]string{" false"}, validate(`false`, `1`))
+
+	_, err := gorequests.CompileJSONSchema([]byte(`{"$ref":"https://example.com/schema"}`))
+	as.NotNil(err)
+	_, err = gorequests.CompileJSONSchema([]byte(`{"type":1}`))
+	as.NotNil(err)
+
+	s := gorequests.MustCompileJSONSchema([]byte(`{"type":"object","properties":{"a":{"type":"number"}}}`))
+	var v interface{}
+	as.Nil(json.Unmarshal([]byte(`{"a":1}`), &v))
+	as.Empty(s.Validate(v))
+}
@@ -79,8 +79,9 @@ const (
 )
 
 type LogMessage struct {
-	Method string `json:"method"`
-	Url    string `json:"url"`
+	Method      string `json:"method"`
+	Url         string `json:"url"`
+	UrlTemplate string `json:"url_template"` // raw url passed to New, without path params rendered
 
 	RequestBody   string      `json:"request_body"`
 	RequestHeader http.Header `json:"request_header"`
@@ -96,4 +97,5 @@ type LogMessage struct {
 
 	LogId       string             `json:"log_id"`
 	RequestType RequestMessageType `json:"request_type"`
+	CacheStatus string             `json:"cache_status"` // HIT, MISS, REVALIDATED, STALE, or empty if cache is not used
 }
@@ -0,0 +1,405 @@
+// Package mock provide a http.RoundTripper for unit testing code which use gorequests.
+//
+// install it on Factory or Session with gorequests.WithTransport, or globally with Activate:
+//
+//	m := mock.New()
+//	m.On(http.MethodGet, "/users/*").WithQuery("page", "1").Reply(http.StatusOK, map[string]interface{}{"id": 1})
+//	m.On(http.MethodPost, "https://api.example.com/users").WithJSONBody(map[string]interface{}{"name": "bob"}).
+//		Reply(http.StatusInternalServerError, "busy").
+//		Reply(http.StatusCreated, `{"id": 2}`)
+//
+//	fac := gorequests.NewFactory(gorequests.WithTransport(m))
+//	// ... code under test use fac
+//	m.AssertExpectations(t)
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jloha/gorequests"
+)
+
+// ErrNoMatch is returned by RoundTrip when request match no expectation
+var ErrNoMatch = errors.New("mock: no expectation match request")
+
+// TestingT is the subset of testing.T used by AssertExpectations
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// Transport is a mock http.RoundTripper, expectations are matched in the order they are added
+type Transport struct {
+	lock         sync.Mutex
+	expectations []*Expectation
+	unmatched    []*http.Request
+}
+
+// New create mock transport
+func New() *Transport {
+	return &Transport{}
+}
+
+// Activate install transport as default transport of all gorequests requests, call returned func to restore
+func (t *Transport) Activate() (restore func()) {
+	prev := gorequests.SetDefaultTransport(t)
+	return func() {
+		gorequests.SetDefaultTransport(prev)
+	}
+}
+
+// On add expectation of method and url pattern.
+//
+// method "" or "*" match any method. pattern match url without query and fragment, if pattern
+// has no scheme, only path of url is matched; "*" in pattern match any characters except "/", see path.Match
+func (t *Transport) On(method, pattern string) *Expectation {
+	return t.add(&Expectation{method: method, pattern: pattern})
+}
+
+// OnRegexp add expectation of method and url regexp, regexp is matched against full url
+func (t *Transport) OnRegexp(method string, re *regexp.Regexp) *Expectation {
+	return t.add(&Expectation{method: method, re: re})
+}
+
+func (t *Transport) add(e *Expectation) *Expectation {
+	e.transport = t
+	e.header = http.Header{}
+	t.lock.Lock()
+	t.expectations = append(t.expectations, e)
+	t.lock.Unlock()
+	return e
+}
+
+// RoundTrip implement http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.lock.Lock()
+	var matched *Expectation
+	var resp *response
+	for _, e := range t.expectations {
+		if e.match(req, body) {
+			matched = e
+			resp = e.next()
+			break
+		}
+	}
+	if matched == nil {
+		t.unmatched = append(t.unmatched, req)
+	}
+	t.lock.Unlock()
+
+	if matched == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, req.Method, req.URL)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("mock: %s %s has no response", req.Method, req.URL)
+	}
+
+	if resp.delay > 0 {
+		timer := time.NewTimer(resp.delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	if resp.err != nil {
+		return nil, resp.err
+	}
+	return resp.build(req), nil
+}
+
+// Unmatched return requests which match no expectation
+func (t *Transport) Unmatched() []*http.Request {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return append([]*http.Request(nil), t.unmatched...)
+}
+
+// AssertExpectations report unmatched requests, and expectations which are called less than expected
+func (t *Transport) AssertExpectations(tt TestingT) bool {
+	if h, ok := tt.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ok := true
+	for _, req := range t.unmatched {
+		tt.Errorf("mock: unmatched request: %s %s", req.Method, req.URL)
+		ok = false
+	}
+	for _, e := range t.expectations {
+		want := e.times
+		if want <= 0 {
+			want = 1
+		}
+		if e.calls < want {
+			tt.Errorf("mock: expectation %s is called %d times, want %d", e, e.calls, want)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Reset remove all expectations and recorded requests
+func (t *Transport) Reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.expectations = nil
+	t.unmatched = nil
+}
+
+// Expectation is an expected request and its responses
+type Expectation struct {
+	transport *Transport
+
+	method   string
+	pattern  string
+	re       *regexp.Regexp
+	query    [][2]string
+	header   http.Header
+	jsonBody interface{}
+	hasJSON  bool
+	matchers []func(req *http.Request, body []byte) bool
+
+	responses []*response
+	times     int
+	calls     int
+}
+
+// WithQuery match query value
+func (e *Expectation) WithQuery(k, v string) *Expectation {
+	e.query = append(e.query, [2]string{k, v})
+	return e
+}
+
+// WithHeader match header value
+func (e *Expectation) WithHeader(k, v string) *Expectation {
+	e.header.Add(k, v)
+	return e
+}
+
+// WithJSONBody match request body, both are compared as decoded json, body can be any json marshalable value
+func (e *Expectation) WithJSONBody(body interface{}) *Expectation {
+	e.jsonBody = normalizeJSON(body)
+	e.hasJSON = true
+	return e
+}
+
+// Match add custom matcher, body is the request body
+func (e *Expectation) Match(f func(req *http.Request, body []byte) bool) *Expectation {
+	e.matchers = append(e.matchers, f)
+	return e
+}
+
+// Times limit expectation to match n requests, after that it's skipped, and AssertExpectations
+// require it is called n times; by default expectation match unlimited requests and require at least one call
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Reply add response, body can be string, []byte, or a value which is encoded as json.
+// responses are returned in sequence, and the last one is repeated
+func (e *Expectation) Reply(status int, body interface{}) *Expectation {
+	header := http.Header{}
+	var bs []byte
+	switch v := body.(type) {
+	case nil:
+	case string:
+		bs = []byte(v)
+	case []byte:
+		bs = v
+	default:
+		var err error
+		if bs, err = json.Marshal(v); err != nil {
+			panic(fmt.Sprintf("mock: marshal reply body: %s", err))
+		}
+		header.Set("Content-Type", "application/json")
+	}
+	e.responses = append(e.responses, &response{status: status, header: header, body: bs})
+	return e
+}
+
+// ReplyHeader set header of last added response
+func (e *Expectation) ReplyHeader(k, v string) *Expectation {
+	e.last().header.Add(k, v)
+	return e
+}
+
+// ReplyError add response which fail with err
+func (e *Expectation) ReplyError(err error) *Expectation {
+	e.responses = append(e.responses, &response{header: http.Header{}, err: err})
+	return e
+}
+
+// Delay delay last added response, request context cancellation is respected
+func (e *Expectation) Delay(d time.Duration) *Expectation {
+	e.last().delay = d
+	return e
+}
+
+// Calls return how many requests matched expectation
+func (e *Expectation) Calls() int {
+	e.transport.lock.Lock()
+	defer e.transport.lock.Unlock()
+
+	return e.calls
+}
+
+func (e *Expectation) String() string {
+	method := e.method
+	if method == "" {
+		method = "*"
+	}
+	if e.re != nil {
+		return method + " ~" + e.re.String()
+	}
+	return method + " " + e.pattern
+}
+
+func (e *Expectation) last() *response {
+	if len(e.responses) == 0 {
+		e.Reply(http.StatusOK, nil)
+	}
+	return e.responses[len(e.responses)-1]
+}
+
+// next return response of this call, must be called with transport lock
+func (e *Expectation) next() *response {
+	e.calls++
+	if len(e.responses) == 0 {
+		return nil
+	}
+	if e.calls <= len(e.responses) {
+		return e.responses[e.calls-1]
+	}
+	return e.responses[len(e.responses)-1]
+}
+
+func (e *Expectation) match(req *http.Request, body []byte) bool {
+	if e.times > 0 && e.calls >= e.times {
+		return false
+	}
+	if e.method != "" && e.method != "*" && !strings.EqualFold(e.method, req.Method) {
+		return false
+	}
+	if !e.matchURL(req) {
+		return false
+	}
+	query := req.URL.Query()
+	for _, kv := range e.query {
+		if !contains(query[kv[0]], kv[1]) {
+			return false
+		}
+	}
+	for k, vs := range e.header {
+		for _, v := range vs {
+			if !contains(req.Header.Values(k), v) {
+				return false
+			}
+		}
+	}
+	if e.hasJSON {
+		var actual interface{}
+		if err := json.Unmarshal(body, &actual); err != nil || !reflect.DeepEqual(e.jsonBody, actual) {
+			return false
+		}
+	}
+	for _, f := range e.matchers {
+		if !f(req, body) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Expectation) matchURL(req *http.Request) bool {
+	if e.re != nil {
+		return e.re.MatchString(req.URL.String())
+	}
+	target := req.URL.Path
+	if strings.Contains(e.pattern, "://") {
+		target = req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	}
+	if target == e.pattern {
+		return true
+	}
+	ok, _ := path.Match(e.pattern, target)
+	return ok
+}
+
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+	err    error
+	delay  time.Duration
+}
+
+func (r *response) build(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.status, http.StatusText(r.status)),
+		StatusCode:    r.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}
+}
+
+func normalizeJSON(v interface{}) interface{} {
+	var bs []byte
+	switch v := v.(type) {
+	case string:
+		bs = []byte(v)
+	case []byte:
+		bs = v
+	default:
+		var err error
+		if bs, err = json.Marshal(v); err != nil {
+			panic(fmt.Sprintf("mock: marshal json body: %s", err))
+		}
+	}
+	var res interface{}
+	if err := json.Unmarshal(bs, &res); err != nil {
+		panic(fmt.Sprintf("mock: invalid json body: %s", err))
+	}
+	return res
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
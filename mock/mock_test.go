@@ -0,0 +1,113 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jloha/gorequests"
+	"github.com/jloha/gorequests/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordT struct {
+	errors []string
+}
+
+func (r *recordT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func Test_Mock(t *testing.T) {
+	as := assert.New(t)
+
+	t.Run("match and reply", func(t *testing.T) {
+		m := mock.New()
+		m.On(http.MethodGet, "/users/*").WithQuery("page", "1").WithHeader("X-Token", "t").
+			Reply(http.StatusOK, map[string]interface{}{"id": 1}).ReplyHeader("X-Request-Id", "r1")
+		m.On(http.MethodPost, "https://api.example.com/users").WithJSONBody(`{"name": "bob", "age": 10}`).
+			Reply(http.StatusInternalServerError, "busy").
+			Reply(http.StatusCreated, `{"id": 2}`)
+		fac := gorequests.NewFactory(gorequests.WithTransport(m), gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+		r := fac.New(http.MethodGet, "https://api.example.com/users/1?page=1").WithHeader("X-Token", "t")
+		text, err := r.Text()
+		as.Nil(err)
+		as.Equal(`{"id":1}`, text)
+		as.Equal("r1", r.MustResponseHeaderByKey("X-Request-Id"))
+
+		post := func() (int, string) {
+			r := fac.New(http.MethodPost, "https://api.example.com/users").WithJSON(map[string]interface{}{"age": 10, "name": "bob"})
+			text, err := r.Text()
+			as.Nil(err)
+			return r.MustResponseStatus(), text
+		}
+		status, text := post()
+		as.Equal(http.StatusInternalServerError, status)
+		as.Equal("busy", text)
+		for i := 0; i < 2; i++ {
+			status, text = post()
+			as.Equal(http.StatusCreated, status)
+			as.Equal(`{"id": 2}`, text)
+		}
+
+		as.True(m.AssertExpectations(t))
+	})
+
+	t.Run("error and delay", func(t *testing.T) {
+		m := mock.New()
+		e := m.OnRegexp("", regexp.MustCompile(`/slow$`)).Reply(http.StatusOK, "ok").Delay(time.Second)
+		m.On(http.MethodGet, "/broken").ReplyError(errors.New("connection reset"))
+		fac := gorequests.NewFactory(gorequests.WithTransport(m), gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := fac.New(http.MethodGet, "https://example.com/slow").WithContext(ctx).Text()
+		as.True(errors.Is(err, gorequests.ErrTimeout), err)
+		as.Equal(1, e.Calls())
+
+		_, err = fac.New(http.MethodGet, "https://example.com/broken").Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "connection reset")
+	})
+
+	t.Run("times", func(t *testing.T) {
+		m := mock.New()
+		m.On(http.MethodGet, "/once").Times(1).Reply(http.StatusOK, "first")
+		m.On(http.MethodGet, "/once").Reply(http.StatusOK, "rest")
+		fac := gorequests.NewFactory(gorequests.WithTransport(m), gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+		as.Equal("first", fac.New(http.MethodGet, "https://example.com/once").MustText())
+		as.Equal("rest", fac.New(http.MethodGet, "https://example.com/once").MustText())
+		as.Equal("rest", fac.New(http.MethodGet, "https://example.com/once").MustText())
+	})
+
+	t.Run("assert", func(t *testing.T) {
+		m := mock.New()
+		m.On(http.MethodGet, "/used").Reply(http.StatusOK, nil)
+		m.On(http.MethodGet, "/unused").Reply(http.StatusOK, nil)
+		m.On(http.MethodGet, "/twice").Times(2).Reply(http.StatusOK, nil)
+		restore := m.Activate()
+		defer restore()
+
+		_, err := gorequests.New(http.MethodGet, "https://example.com/used").WithLogger(gorequests.NewDiscardLogger()).Text()
+		as.Nil(err)
+		_, err = gorequests.New(http.MethodGet, "https://example.com/twice").WithLogger(gorequests.NewDiscardLogger()).Text()
+		as.Nil(err)
+		_, err = gorequests.New(http.MethodGet, "https://example.com/unknown").WithLogger(gorequests.NewDiscardLogger()).Text()
+		as.True(errors.Is(err, mock.ErrNoMatch), err)
+		as.Len(m.Unmatched(), 1)
+
+		rt := &recordT{}
+		as.False(m.AssertExpectations(rt))
+		as.Equal([]string{
+			"mock: unmatched request: GET https://example.com/unknown",
+			"mock: expectation GET /unused is called 0 times, want 1",
+			"mock: expectation GET /twice is called 1 times, want 2",
+		}, rt.errors)
+	})
+}
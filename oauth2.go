@@ -0,0 +1,247 @@
+package gorequests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrOAuth2NoRefreshToken is returned when refresh token grant has no refresh token
+var ErrOAuth2NoRefreshToken = errors.New("gorequests: oauth2 refresh token is empty")
+
+// default values of OAuth2Config
+const (
+	defaultOAuth2ExpiryMargin = 10 * time.Second
+	defaultOAuth2Timeout      = 30 * time.Second
+)
+
+// OAuth2AuthStyle is how client credentials are sent to token endpoint
+type OAuth2AuthStyle int
+
+const (
+	OAuth2AuthStyleHeader OAuth2AuthStyle = iota // basic auth header, see RFC 6749 section 2.3.1
+	OAuth2AuthStyleBody                          // client_id and client_secret form fields
+)
+
+// OAuth2Config is the config of OAuth2TokenSource, refresh token grant is used if RefreshToken is set,
+// otherwise client credentials grant is used
+type OAuth2Config struct {
+	TokenURL       string
+	ClientID       string
+	ClientSecret   string
+	Scopes         []string
+	RefreshToken   string
+	EndpointParams url.Values      // extra form fields sent to token endpoint, e.g. audience
+	AuthStyle      OAuth2AuthStyle // default is OAuth2AuthStyleHeader
+	ExpiryMargin   time.Duration   // token is refreshed ExpiryMargin before it expires, default is 10s
+	// Requester create request to token endpoint,
+	// default is a factory with 30s timeout and discard logger, so tokens are not logged
+	Requester Requester
+}
+
+// OAuth2Token is the token response of token endpoint
+type OAuth2Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresIn    int64     `json:"expires_in,omitempty"` // seconds
+	Scope        string    `json:"scope,omitempty"`
+	Expiry       time.Time `json:"-"` // zero if token never expires
+}
+
+// OAuth2TokenSource fetch token from token endpoint, and cache it until it expires,
+// concurrent callers share one refresh, it's safe for concurrent use
+//
+//	source := gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{
+//		TokenURL:     "https://auth/oauth/token",
+//		ClientID:     "id",
+//		ClientSecret: "secret",
+//	})
+//	fac := gorequests.NewFactory(gorequests.WithOAuth2(source))
+type OAuth2TokenSource struct {
+	config OAuth2Config
+
+	lock         sync.Mutex
+	token        *OAuth2Token
+	refreshToken string
+	refreshing   *oauth2Refresh
+}
+
+// oauth2Refresh is the in-flight refresh shared by callers
+type oauth2Refresh struct {
+	done  chan struct{}
+	token *OAuth2Token
+	err   error
+}
+
+// NewOAuth2TokenSource create token source of config
+func NewOAuth2TokenSource(config OAuth2Config) *OAuth2TokenSource {
+	if config.ExpiryMargin <= 0 {
+		config.ExpiryMargin = defaultOAuth2ExpiryMargin
+	}
+	if config.Requester == nil {
+		config.Requester = NewFactory(WithTimeout(defaultOAuth2Timeout), WithLogger(NewDiscardLogger()))
+	}
+	return &OAuth2TokenSource{config: config, refreshToken: config.RefreshToken}
+}
+
+// Token return cached token, or fetch new one if it's absent or about to expire
+func (s *OAuth2TokenSource) Token(ctx context.Context) (*OAuth2Token, error) {
+	return s.token0(ctx, false, "")
+}
+
+// Refresh fetch new token even if cached token is valid
+func (s *OAuth2TokenSource) Refresh(ctx context.Context) (*OAuth2Token, error) {
+	return s.token0(ctx, true, "")
+}
+
+// TokenSource return bearer token source of s, see WithBearerTokenSource,
+// use WithOAuth2 to also retry requests rejected with 401
+func (s *OAuth2TokenSource) TokenSource() TokenSource {
+	return func(ctx context.Context) (string, error) {
+		token, err := s.Token(ctx)
+		if err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
+	}
+}
+
+// token0 return valid cached token, or wait for shared refresh,
+// cached token is refreshed if force is true and it's the same as rejected access token, so concurrent rejected callers refresh once
+func (s *OAuth2TokenSource) token0(ctx context.Context, force bool, rejected string) (*OAuth2Token, error) {
+	s.lock.Lock()
+	if s.token != nil && !(force && (rejected == "" || rejected == s.token.AccessToken)) && s.valid(s.token) {
+		token := s.token
+		s.lock.Unlock()
+		return token, nil
+	}
+	call := s.refreshing
+	if call == nil {
+		call = &oauth2Refresh{done: make(chan struct{})}
+		s.refreshing = call
+		go s.refresh(detachedContext{ctx}, call)
+	}
+	s.lock.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *OAuth2TokenSource) valid(token *OAuth2Token) bool {
+	return token.Expiry.IsZero() || time.Now().Add(s.config.ExpiryMargin).Before(token.Expiry)
+}
+
+func (s *OAuth2TokenSource) refresh(ctx context.Context, call *oauth2Refresh) {
+	s.lock.Lock()
+	refreshToken := s.refreshToken
+	s.lock.Unlock()
+
+	token, err := s.fetch(ctx, refreshToken)
+
+	s.lock.Lock()
+	if err == nil {
+		s.token = token
+		if token.RefreshToken != "" {
+			s.refreshToken = token.RefreshToken
+		}
+	}
+	s.refreshing = nil
+	s.lock.Unlock()
+
+	call.token, call.err = token, err
+	close(call.done)
+}
+
+// fetch request token endpoint
+func (s *OAuth2TokenSource) fetch(ctx context.Context, refreshToken string) (*OAuth2Token, error) {
+	form := url.Values{}
+	for k, v := range s.config.EndpointParams {
+		form[k] = append([]string(nil), v...)
+	}
+	if s.config.RefreshToken != "" {
+		if refreshToken == "" {
+			return nil, ErrOAuth2NoRefreshToken
+		}
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", refreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+
+	req := s.config.Requester.New(http.MethodPost, s.config.TokenURL).WithContext(ctx)
+	if s.config.AuthStyle == OAuth2AuthStyleBody {
+		form.Set("client_id", s.config.ClientID)
+		if s.config.ClientSecret != "" {
+			form.Set("client_secret", s.config.ClientSecret)
+		}
+	} else {
+		req.WithBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+	req.WithBody(form.Encode()).
+		WithHeader("Content-Type", "application/x-www-form-urlencoded").
+		WithHeader("Accept", "application/json").
+		WithExpectStatus()
+
+	start := time.Now()
+	token := new(OAuth2Token)
+	if err := req.Unmarshal(token); err != nil {
+		return nil, fmt.Errorf("oauth2: fetch token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: fetch token: %w: access_token is empty", ErrDecode)
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = start.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// oauth2Auth set bearer token of OAuth2TokenSource, and retry once with refreshed token on 401
+type oauth2Auth struct {
+	source *OAuth2TokenSource
+}
+
+func (a *oauth2Auth) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if err := bufferRequestBody(req); err != nil {
+		return nil, err
+	}
+	token, err := a.source.Token(req.Context())
+	if err != nil {
+		closeRequestBody(req)
+		return nil, &authError{err: err}
+	}
+	send := req.Clone(req.Context())
+	send.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	resp, err := next.RoundTrip(send)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	refreshed, err := a.source.token0(req.Context(), true, token.AccessToken)
+	if err != nil {
+		return resp, nil // keep the 401 response, refresh error is less useful than it
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	retry, err := cloneRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	retry.Header.Set("Authorization", "Bearer "+refreshed.AccessToken)
+	return next.RoundTrip(retry)
+}
@@ -0,0 +1,222 @@
+package gorequests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+// newOAuth2Server issue token-1, token-2 ... to client id:secret, expires_in is given by query of token url
+func newOAuth2Server(fetched *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		id, secret, ok := r.BasicAuth()
+		if ok {
+			// client credentials are form encoded in basic auth, see RFC 6749 section 2.3.1
+			id, _ = url.QueryUnescape(id)
+			secret, _ = url.QueryUnescape(secret)
+		} else {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if id != "id" || secret != "s:ecret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		switch r.PostForm.Get("grant_type") {
+		case "client_credentials":
+		case "refresh_token":
+			if !strings.HasPrefix(r.PostForm.Get("refresh_token"), "refresh") {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		expiresIn := r.URL.Query().Get("expires_in")
+		if expiresIn == "" {
+			expiresIn = "0"
+		}
+		time.Sleep(10 * time.Millisecond)
+		n := atomic.AddInt32(fetched, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%s,"refresh_token":"refresh-%d","scope":%q}`,
+			n, expiresIn, n, r.PostForm.Get("scope")+r.PostForm.Get("refresh_token"))
+	}))
+}
+
+func Test_OAuth2(t *testing.T) {
+	as := assert.New(t)
+	ctx := context.Background()
+
+	t.Run("client credentials", func(t *testing.T) {
+		var fetched int32
+		server := newOAuth2Server(&fetched)
+		defer server.Close()
+
+		source := gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{
+			TokenURL: server.URL + "?expires_in=3600", ClientID: "id", ClientSecret: "s:ecret", Scopes: []string{"a", "b"},
+		})
+		token, err := source.Token(ctx)
+		as.Nil(err)
+		as.Equal("token-1", token.AccessToken)
+		as.Equal("a b", token.Scope)
+		as.WithinDuration(time.Now().Add(time.Hour), token.Expiry, time.Minute)
+
+		// concurrent callers share cached token and refresh
+		var wg sync.WaitGroup
+		tokens := make([]string, 20)
+		for i := range tokens {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				token, err := source.Refresh(ctx)
+				if err == nil {
+					tokens[i] = token.AccessToken
+				}
+			}(i)
+		}
+		wg.Wait()
+		as.Equal(int32(2), fetched)
+		for _, token := range tokens {
+			as.Equal("token-2", token)
+		}
+		token, err = source.Token(ctx)
+		as.Nil(err)
+		as.Equal("token-2", token.AccessToken)
+		as.Equal(int32(2), fetched)
+
+		// client credentials in body
+		source = gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{
+			TokenURL: server.URL, ClientID: "id", ClientSecret: "s:ecret", AuthStyle: gorequests.OAuth2AuthStyleBody,
+		})
+		token, err = source.Token(ctx)
+		as.Nil(err)
+		as.Equal("token-3", token.AccessToken)
+		as.True(token.Expiry.IsZero())
+	})
+
+	t.Run("refresh token and expiry margin", func(t *testing.T) {
+		var fetched int32
+		server := newOAuth2Server(&fetched)
+		defer server.Close()
+
+		source := gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{
+			TokenURL: server.URL + "?expires_in=5", ClientID: "id", ClientSecret: "s:ecret", RefreshToken: "refresh-0",
+		})
+		token, err := source.Token(ctx)
+		as.Nil(err)
+		as.Equal("token-1", token.AccessToken)
+		as.Equal("refresh-0", token.Scope) // server echo refresh token
+
+		// expires within default 10s margin, rotated refresh token is used
+		token, err = source.Token(ctx)
+		as.Nil(err)
+		as.Equal("token-2", token.AccessToken)
+		as.Equal("refresh-1", token.Scope)
+
+		source = gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{
+			TokenURL: server.URL + "?expires_in=5", ClientID: "id", ClientSecret: "s:ecret", RefreshToken: "refresh-0", ExpiryMargin: time.Second,
+		})
+		token, err = source.Token(ctx)
+		as.Nil(err)
+		cached, err := source.Token(ctx)
+		as.Nil(err)
+		as.Equal(token, cached)
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		var fetched int32
+		server := newOAuth2Server(&fetched)
+		defer server.Close()
+
+		_, err := gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{
+			TokenURL: server.URL, ClientID: "id", ClientSecret: "wrong",
+		}).Token(ctx)
+		var httpErr *gorequests.HTTPError
+		as.True(errors.As(err, &httpErr))
+		as.Equal(http.StatusUnauthorized, httpErr.StatusCode)
+		as.Contains(err.Error(), "oauth2: fetch token")
+
+		_, err = gorequests.New(http.MethodGet, server.URL).WithLogger(gorequests.NewDiscardLogger()).
+			WithOAuth2(gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{TokenURL: server.URL, ClientID: "id", ClientSecret: "wrong"})).Text()
+		as.Equal(gorequests.KindAuth, gorequests.KindOf(err))
+		as.True(errors.Is(err, gorequests.ErrAuth))
+
+		_, err = gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{
+			TokenURL: server.URL, ClientID: "id", ClientSecret: "s:ecret", RefreshToken: "bad",
+		}).Token(ctx)
+		as.True(errors.Is(err, gorequests.ErrUnexpectedStatus))
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{
+			TokenURL: server.URL, ClientID: "id", ClientSecret: "s:ecret",
+		}).Token(canceled)
+		as.True(errors.Is(err, context.Canceled))
+	})
+
+	t.Run("retry on 401", func(t *testing.T) {
+		var fetched, rejected int32
+		server := newOAuth2Server(&fetched)
+		defer server.Close()
+
+		// token-1 is revoked
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth := r.Header.Get("Authorization"); auth == "Bearer token-1" || !strings.HasPrefix(auth, "Bearer ") {
+				atomic.AddInt32(&rejected, 1)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body := new(strings.Builder)
+			_, _ = fmt.Fprintf(body, "%s %s ", r.Header.Get("Authorization"), filepath.Base(r.URL.Path))
+			buf := make([]byte, 16)
+			n, _ := r.Body.Read(buf)
+			body.Write(buf[:n])
+			_, _ = w.Write([]byte(body.String()))
+		}))
+		defer api.Close()
+
+		source := gorequests.NewOAuth2TokenSource(gorequests.OAuth2Config{
+			TokenURL: server.URL, ClientID: "id", ClientSecret: "s:ecret",
+		})
+		fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()), gorequests.WithOAuth2(source))
+		_, err := source.Token(ctx)
+		as.Nil(err)
+
+		var wg sync.WaitGroup
+		texts := make([]string, 10)
+		for i := range texts {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				texts[i], _ = fac.New(http.MethodPost, api.URL+"/"+fmt.Sprint(i)).WithBody(strings.NewReader("body")).Text()
+			}(i)
+		}
+		wg.Wait()
+		for i, text := range texts {
+			as.Equal(fmt.Sprintf("Bearer token-2 %d body", i), text)
+		}
+		as.Equal(int32(2), fetched)
+		as.True(rejected >= 1)
+
+		// token source is usable as bearer token source
+		text, err := gorequests.New(http.MethodGet, api.URL+"/a").WithLogger(gorequests.NewDiscardLogger()).WithBearerTokenSource(source.TokenSource()).Text()
+		as.Nil(err)
+		as.Equal("Bearer token-2 a ", text)
+	})
+}
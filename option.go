@@ -1,6 +1,9 @@
 package gorequests
 
 import (
+	"fmt"
+	"net/http"
+	"reflect"
 	"time"
 )
 
@@ -33,3 +36,154 @@ func WithQuery(key, val string) RequestOption {
 		return nil
 	}
 }
+
+func WithBasicAuth(username, password string) RequestOption {
+	return func(req *Request) error {
+		req.WithBasicAuth(username, password)
+		return nil
+	}
+}
+
+func WithBearerToken(token string) RequestOption {
+	return func(req *Request) error {
+		req.WithBearerToken(token)
+		return nil
+	}
+}
+
+func WithBearerTokenSource(source TokenSource) RequestOption {
+	return func(req *Request) error {
+		req.WithBearerTokenSource(source)
+		return nil
+	}
+}
+
+// WithDigestAuth authenticate requests with digest access authentication, see Request.WithDigestAuth,
+// challenge is shared by requests, so following requests to the same host are authenticated without 401 round trip
+func WithDigestAuth(username, password string) RequestOption {
+	auth := newDigestAuth(username, password)
+	return func(req *Request) error {
+		req.configParamFactor(func(r *Request) {
+			r.auth = auth
+		})
+		return nil
+	}
+}
+
+// WithOAuth2 authenticate requests with bearer token of OAuth2TokenSource, see Request.WithOAuth2,
+// token is cached by source, so requests share it and refresh it once when it expires
+func WithOAuth2(source *OAuth2TokenSource) RequestOption {
+	auth := &oauth2Auth{source: source}
+	return func(req *Request) error {
+		req.configParamFactor(func(r *Request) {
+			r.auth = auth
+		})
+		return nil
+	}
+}
+
+func WithAWSSigV4(credentials AWSCredentials, region, service string) RequestOption {
+	return func(req *Request) error {
+		req.WithAWSSigV4(credentials, region, service)
+		return nil
+	}
+}
+
+func WithSigner(signer Signer) RequestOption {
+	return func(req *Request) error {
+		req.WithSigner(signer)
+		return nil
+	}
+}
+
+func WithBaseURL(baseURL string) RequestOption {
+	return func(req *Request) error {
+		req.WithBaseURL(baseURL)
+		return nil
+	}
+}
+
+func WithAllowBaseURLEscape(allow bool) RequestOption {
+	return func(req *Request) error {
+		req.WithAllowBaseURLEscape(allow)
+		return nil
+	}
+}
+
+func WithExpectStatus(codes ...int) RequestOption {
+	return func(req *Request) error {
+		req.WithExpectStatus(codes...)
+		return nil
+	}
+}
+
+// WithErrorResult set error result of every request to a new value with same type of val,
+// val must be a pointer, see Request.WithErrorResult
+func WithErrorResult(val interface{}) RequestOption {
+	return func(req *Request) error {
+		typ := reflect.TypeOf(val)
+		if typ == nil || typ.Kind() != reflect.Ptr {
+			return fmt.Errorf("error result need pointer, but got %T", val)
+		}
+		req.WithErrorResult(reflect.New(typ.Elem()).Interface())
+		return nil
+	}
+}
+
+func WithEnvelope(envelope *Envelope) RequestOption {
+	return func(req *Request) error {
+		req.WithEnvelope(envelope)
+		return nil
+	}
+}
+
+func WithJSONSchema(schema *JSONSchema, mode SchemaMode) RequestOption {
+	return func(req *Request) error {
+		req.WithJSONSchema(schema, mode)
+		return nil
+	}
+}
+
+// WithRouteJSONSchema set json schema of requests which match method and route,
+// route is url template passed to New, query is ignored, and only path is compared if route has no host:
+//
+//	gorequests.NewFactory(gorequests.WithRouteJSONSchema(http.MethodGet, "/users/{id}", userSchema, gorequests.SchemaModeEnforce))
+func WithRouteJSONSchema(method, route string, schema *JSONSchema, mode SchemaMode) RequestOption {
+	return func(req *Request) error {
+		if req.isRoute(method, route) {
+			req.WithJSONSchema(schema, mode)
+		}
+		return nil
+	}
+}
+
+func WithCache(cache *Cache) RequestOption {
+	return func(req *Request) error {
+		req.WithCache(cache)
+		return nil
+	}
+}
+
+// WithCoalescing make identical in-flight GET and HEAD requests created by Factory or Session share one upstream call,
+// headers are the request headers which are part of the identity of request in addition to credential headers, see NewCoalescer
+func WithCoalescing(headers ...string) RequestOption {
+	coalescer := NewCoalescer(headers...)
+	return func(req *Request) error {
+		req.WithCoalescer(coalescer)
+		return nil
+	}
+}
+
+func WithTransport(rt http.RoundTripper) RequestOption {
+	return func(req *Request) error {
+		req.WithTransport(rt)
+		return nil
+	}
+}
+
+func WithHARRecorder(recorder *HARRecorder) RequestOption {
+	return func(req *Request) error {
+		req.WithHARRecorder(recorder)
+		return nil
+	}
+}
@@ -0,0 +1,374 @@
+package gorequests
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type queryArrayFormat int
+
+const (
+	queryArrayRepeat   queryArrayFormat = iota // a=1&a=2
+	queryArrayBrackets                         // a[]=1&a[]=2
+	queryArrayComma                            // a=1,2
+)
+
+const (
+	queryTimeUnix      = "unix"
+	queryTimeUnixMilli = "unixmilli"
+	queryTimeUnixNano  = "unixnano"
+)
+
+type queryField struct {
+	index     []int
+	field     string // go field name, used in error message
+	name      string // query key
+	omitEmpty bool
+	format    queryArrayFormat
+	layout    string
+}
+
+var (
+	queryFieldsCache  sync.Map
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	fmtStringerType   = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+func queryToMap(v interface{}) (map[string][]string, error) {
+	vv, ok := indirectValue(reflect.ValueOf(v))
+	if !ok {
+		return map[string][]string{}, nil
+	}
+	if vv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: need struct, but got %s", ErrUnsupportedQuery, vv.Kind())
+	}
+
+	vals := map[string][]string{}
+	if err := encodeQueryStruct(vals, "", "", vv); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+func encodeQueryStruct(vals map[string][]string, prefix, fieldPrefix string, v reflect.Value) error {
+	fields, err := getTagFields(v.Type(), "query")
+	if err != nil {
+		return err
+	}
+
+	for i := range fields {
+		f := &fields[i]
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			continue
+		}
+		key, field := f.name, f.field
+		if prefix != "" {
+			key = prefix + "[" + f.name + "]"
+		}
+		if fieldPrefix != "" {
+			field = fieldPrefix + "." + f.field
+		}
+		if err := encodeQueryValue(vals, key, field, f, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeQueryValue(vals map[string][]string, key, field string, f *queryField, v reflect.Value) error {
+	if f.omitEmpty && isEmptyValue(v) {
+		return nil
+	}
+	v, ok := indirectValue(v)
+	if !ok {
+		return nil
+	}
+
+	s, ok, err := formatQueryScalar(v, f)
+	if err != nil {
+		return fmt.Errorf("query field %s: %w", field, err)
+	} else if ok {
+		vals[key] = append(vals[key], s)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		items := make([]string, 0, v.Len())
+		for j := 0; j < v.Len(); j++ {
+			item, ok := indirectValue(v.Index(j))
+			if !ok {
+				continue
+			}
+			s, ok, err := formatQueryScalar(item, f)
+			if err != nil {
+				return fmt.Errorf("query field %s[%d]: %w", field, j, err)
+			} else if !ok {
+				return fmt.Errorf("query field %s[%d]: %w: %s", field, j, ErrUnsupportedQuery, item.Type())
+			}
+			items = append(items, s)
+		}
+		switch f.format {
+		case queryArrayBrackets:
+			vals[key+"[]"] = append(vals[key+"[]"], items...)
+		case queryArrayComma:
+			if len(items) > 0 {
+				vals[key] = append(vals[key], strings.Join(items, ","))
+			}
+		default:
+			vals[key] = append(vals[key], items...)
+		}
+		return nil
+	case reflect.Map:
+		sub := *f
+		sub.omitEmpty = false
+		type mapItem struct {
+			key string
+			val reflect.Value
+		}
+		items := make([]mapItem, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			mk, ok := indirectValue(iter.Key())
+			if !ok {
+				continue
+			}
+			s, ok, err := formatQueryScalar(mk, &sub)
+			if err != nil {
+				return fmt.Errorf("query field %s key: %w", field, err)
+			} else if !ok {
+				return fmt.Errorf("query field %s key: %w: %s", field, ErrUnsupportedQuery, mk.Type())
+			}
+			items = append(items, mapItem{key: s, val: iter.Value()})
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].key < items[j].key })
+		for _, item := range items {
+			if err := encodeQueryValue(vals, key+"["+item.key+"]", field+"["+item.key+"]", &sub, item.val); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		return encodeQueryStruct(vals, key, field, v)
+	}
+
+	return fmt.Errorf("query field %s: %w: %s", field, ErrUnsupportedQuery, v.Type())
+}
+
+// formatQueryScalar format single value, return false if v is not a scalar
+func formatQueryScalar(v reflect.Value, f *queryField) (string, bool, error) {
+	if v.Type() == timeType {
+		return formatQueryTime(v.Interface().(time.Time), f.layout), true, nil
+	}
+	if m, ok := valueAs(v, textMarshalerType); ok {
+		bs, err := m.(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", false, err
+		}
+		return string(bs), true, nil
+	}
+	if m, ok := valueAs(v, fmtStringerType); ok {
+		return m.(fmt.Stringer).String(), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true, nil
+	}
+	return "", false, nil
+}
+
+func formatQueryTime(t time.Time, layout string) string {
+	switch layout {
+	case queryTimeUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case queryTimeUnixMilli:
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	case queryTimeUnixNano:
+		return strconv.FormatInt(t.UnixNano(), 10)
+	case "":
+		return t.Format(time.RFC3339)
+	}
+	return t.Format(layout)
+}
+
+// valueAs return v as interface typ, also try pointer receiver
+func valueAs(v reflect.Value, typ reflect.Type) (interface{}, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	if v.Type().Implements(typ) {
+		return v.Interface(), true
+	}
+	if reflect.PtrTo(v.Type()).Implements(typ) {
+		if v.CanAddr() {
+			return v.Addr().Interface(), true
+		}
+		pv := reflect.New(v.Type())
+		pv.Elem().Set(v)
+		return pv.Interface(), true
+	}
+	return nil, false
+}
+
+type tagFieldsKey struct {
+	typ reflect.Type
+	tag string
+}
+
+// getTagFields return fields of typ with tag, options of tag are the same as query tag, see WithQueryStruct
+func getTagFields(typ reflect.Type, tagName string) ([]queryField, error) {
+	key := tagFieldsKey{typ: typ, tag: tagName}
+	if v, ok := queryFieldsCache.Load(key); ok {
+		return v.([]queryField), nil
+	}
+
+	fields, err := parseTagFields(typ, tagName, nil, "", map[reflect.Type]bool{typ: true})
+	if err != nil {
+		return nil, err
+	}
+
+	queryFieldsCache.Store(key, fields)
+	return fields, nil
+}
+
+// parseTagFields parse fields of typ recursively, visited are struct types being parsed, which are not flattened again
+func parseTagFields(typ reflect.Type, tagName string, index []int, fieldPrefix string, visited map[reflect.Type]bool) ([]queryField, error) {
+	fields := []queryField{}
+	for i := 0; i < typ.NumField(); i++ {
+		itemT := typ.Field(i)
+		fieldName := itemT.Name
+		if fieldPrefix != "" {
+			fieldName = fieldPrefix + "." + itemT.Name
+		}
+		itemIndex := append(append([]int{}, index...), i)
+
+		tag, hasTag := itemT.Tag.Lookup(tagName)
+		if tag == "-" {
+			continue
+		}
+		if !hasTag || tag == "" {
+			// flatten embedded struct without query tag
+			if itemT.Anonymous {
+				embedded := itemT.Type
+				if embedded.Kind() == reflect.Ptr {
+					embedded = embedded.Elem()
+				}
+				if embedded.Kind() == reflect.Struct && !visited[embedded] {
+					visited[embedded] = true
+					sub, err := parseTagFields(embedded, tagName, itemIndex, fieldName, visited)
+					delete(visited, embedded)
+					if err != nil {
+						return nil, err
+					}
+					fields = append(fields, sub...)
+				}
+			}
+			continue
+		}
+		if itemT.PkgPath != "" {
+			continue // unexported
+		}
+
+		f, err := parseQueryTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s field %s: %w", tagName, fieldName, err)
+		}
+		if layout := itemT.Tag.Get("layout"); layout != "" {
+			f.layout = layout
+		}
+		f.index = itemIndex
+		f.field = fieldName
+		if f.name == "" {
+			f.name = itemT.Name
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func parseQueryTag(tag string) (queryField, error) {
+	parts := strings.Split(tag, ",")
+	f := queryField{name: parts[0]}
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			f.omitEmpty = true
+		case "repeat":
+			f.format = queryArrayRepeat
+		case "brackets":
+			f.format = queryArrayBrackets
+		case "comma":
+			f.format = queryArrayComma
+		case queryTimeUnix, queryTimeUnixMilli, queryTimeUnixNano:
+			f.layout = opt
+		case "":
+		default:
+			return f, fmt.Errorf("unknown tag option %q", opt)
+		}
+	}
+	return f, nil
+}
+
+// fieldByIndex like reflect.Value.FieldByIndex, but return false when meet nil embedded pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			var ok bool
+			if v, ok = indirectValue(v); !ok {
+				return reflect.Value{}, false
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// indirectValue deref pointer and interface, return false when meet nil
+func indirectValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		return v.IsZero()
+	}
+	return false
+}
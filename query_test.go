@@ -0,0 +1,198 @@
+package gorequests_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+type testQueryID int
+
+func (r testQueryID) String() string {
+	return "id-" + strings.Repeat("x", int(r))
+}
+
+type testQueryText struct{ v string }
+
+func (r *testQueryText) MarshalText() ([]byte, error) {
+	return []byte("text:" + r.v), nil
+}
+
+type TestQueryPage struct {
+	Page int `query:"page"`
+	Size int `query:"size,omitempty"`
+}
+
+func Test_QueryStruct(t *testing.T) {
+	as := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.RawQuery))
+	}))
+	defer server.Close()
+
+	queryOf := func(v interface{}) (url.Values, error) {
+		text, err := gorequests.New(http.MethodGet, server.URL).WithQueryStruct(v).WithLogger(gorequests.NewDiscardLogger()).Text()
+		if err != nil {
+			return nil, err
+		}
+		return url.ParseQuery(text)
+	}
+
+	t.Run("scalar", func(t *testing.T) {
+		two := 2
+		var nilInt *int
+		q, err := queryOf(&struct {
+			S   string        `query:"s"`
+			B   bool          `query:"b"`
+			F32 float32       `query:"f32"`
+			F64 float64       `query:"f64"`
+			U   uint8         `query:"u"`
+			P   *int          `query:"p"`
+			N   *int          `query:"n"`
+			I   testQueryID   `query:"i"`
+			T   testQueryText `query:"t"`
+			Any interface{}   `query:"any"`
+			X   string
+		}{S: "a b", B: true, F32: 1.5, F64: 0.1, U: 7, P: &two, N: nilInt, I: 3, T: testQueryText{v: "v"}, Any: 5, X: "ignored"})
+		as.Nil(err)
+		as.Equal("a b", q.Get("s"))
+		as.Equal("true", q.Get("b"))
+		as.Equal("1.5", q.Get("f32"))
+		as.Equal("0.1", q.Get("f64"))
+		as.Equal("7", q.Get("u"))
+		as.Equal("2", q.Get("p"))
+		as.Equal("id-xxx", q.Get("i"))
+		as.Equal("text:v", q.Get("t"))
+		as.Equal("5", q.Get("any"))
+		_, ok := q["n"]
+		as.False(ok)
+		_, ok = q["X"]
+		as.False(ok)
+	})
+
+	t.Run("omitempty", func(t *testing.T) {
+		q, err := queryOf(struct {
+			A string    `query:"a,omitempty"`
+			B []int     `query:"b,omitempty"`
+			T time.Time `query:"t,omitempty"`
+			C int       `query:"c"`
+		}{})
+		as.Nil(err)
+		as.Equal(url.Values{"c": {"0"}}, q)
+	})
+
+	t.Run("array format", func(t *testing.T) {
+		q, err := queryOf(struct {
+			Repeat   []int    `query:"r"`
+			Brackets []string `query:"b,brackets"`
+			Comma    [2]int   `query:"c,comma"`
+		}{Repeat: []int{1, 2}, Brackets: []string{"x", "y"}, Comma: [2]int{3, 4}})
+		as.Nil(err)
+		as.Equal([]string{"1", "2"}, q["r"])
+		as.Equal([]string{"x", "y"}, q["b[]"])
+		as.Equal([]string{"3,4"}, q["c"])
+	})
+
+	t.Run("time", func(t *testing.T) {
+		tm := time.Date(2021, 1, 2, 3, 4, 5, 6000000, time.UTC)
+		q, err := queryOf(struct {
+			Default time.Time  `query:"d"`
+			Layout  time.Time  `query:"l" layout:"2006-01-02"`
+			Unix    time.Time  `query:"u,unix"`
+			Milli   *time.Time `query:"m,unixmilli"`
+		}{Default: tm, Layout: tm, Unix: tm, Milli: &tm})
+		as.Nil(err)
+		as.Equal("2021-01-02T03:04:05Z", q.Get("d"))
+		as.Equal("2021-01-02", q.Get("l"))
+		as.Equal("1609556645", q.Get("u"))
+		as.Equal("1609556645006", q.Get("m"))
+	})
+
+	t.Run("nested, map and embedded", func(t *testing.T) {
+		type filter struct {
+			Name string   `query:"name"`
+			Tags []string `query:"tags,comma"`
+		}
+		q, err := queryOf(&struct {
+			TestQueryPage
+			*testQueryEmbedded
+			Filter filter            `query:"filter"`
+			Labels map[string]string `query:"labels"`
+		}{
+			TestQueryPage: TestQueryPage{Page: 1},
+			Filter:        filter{Name: "n", Tags: []string{"a", "b"}},
+			Labels:        map[string]string{"k1": "v1", "k2": "v2"},
+		})
+		as.Nil(err)
+		as.Equal(url.Values{
+			"page":         {"1"},
+			"filter[name]": {"n"},
+			"filter[tags]": {"a,b"},
+			"labels[k1]":   {"v1"},
+			"labels[k2]":   {"v2"},
+		}, q)
+	})
+
+	t.Run("recursive embedded", func(t *testing.T) {
+		q, err := queryOf(&testQueryNode{X: 1, testQueryNode: &testQueryNode{X: 2}})
+		as.Nil(err)
+		as.Equal(url.Values{"x": {"1"}}, q)
+
+		q, err = queryOf(testQueryCycleA{A: "a", testQueryCycleB: &testQueryCycleB{B: "b"}})
+		as.Nil(err)
+		as.Equal(url.Values{"a": {"a"}, "b": {"b"}}, q)
+	})
+
+	t.Run("error name field", func(t *testing.T) {
+		_, err := queryOf(struct {
+			Inner struct {
+				C chan int `query:"c"`
+			} `query:"inner"`
+		}{})
+		as.NotNil(err)
+		as.Contains(err.Error(), "query field Inner.C")
+
+		_, err = queryOf(struct {
+			A string `query:"a,unknown"`
+		}{})
+		as.NotNil(err)
+		as.Contains(err.Error(), "query field A")
+	})
+
+	t.Run("nil and not struct", func(t *testing.T) {
+		var p *TestQueryPage
+		q, err := queryOf(p)
+		as.Nil(err)
+		as.Empty(q)
+
+		_, err = queryOf(1)
+		as.NotNil(err)
+		as.Contains(err.Error(), "need struct")
+	})
+}
+
+type testQueryEmbedded struct {
+	Embedded string `query:"embedded"`
+}
+
+type testQueryNode struct {
+	*testQueryNode
+	X int `query:"x"`
+}
+
+type testQueryCycleA struct {
+	*testQueryCycleB
+	A string `query:"a"`
+}
+
+type testQueryCycleB struct {
+	*testQueryCycleA
+	B string `query:"b"`
+}
@@ -3,7 +3,6 @@ package gorequests
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -65,6 +64,11 @@ func (r *Request) Method() string {
 	return r.method
 }
 
+// CacheStatus cache status of response, empty if cache is not used or request is not cacheable
+func (r *Request) CacheStatus() CacheStatus {
+	return r.cacheStatus
+}
+
 // RequestHeader request header
 func (r *Request) RequestHeader() http.Header {
 	return r.header
@@ -103,6 +107,13 @@ func (r *Request) WithIgnoreSSL(ignore bool) *Request {
 	})
 }
 
+// WithTransport set base transport of request, WithIgnoreSSL only apply to *http.Transport
+func (r *Request) WithTransport(rt http.RoundTripper) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.transport = rt
+	})
+}
+
 // WithHeader set one header k-v map
 func (r *Request) WithHeader(k, v string) *Request {
 	return r.configParamFactor(func(r *Request) {
@@ -128,6 +139,86 @@ func (r *Request) configHeader(k, v string) {
 	//}
 }
 
+// WithBasicAuth set Authorization header of basic auth
+func (r *Request) WithBasicAuth(username, password string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.header.Set("Authorization", basicAuthorization(username, password))
+	})
+}
+
+// WithBearerToken set Authorization header of bearer token
+func (r *Request) WithBearerToken(token string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.header.Set("Authorization", "Bearer "+token)
+	})
+}
+
+// WithBearerTokenSource set Authorization header with bearer token of source when request is sent,
+// redirected requests to other hosts are sent without it
+func (r *Request) WithBearerTokenSource(source TokenSource) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.auth = &bearerAuth{source: source}
+	})
+}
+
+// WithDigestAuth authenticate request with RFC 7616 digest access authentication,
+// request is sent again with credentials when server respond 401 with digest challenge,
+// MD5, SHA-256 and their -sess variants with qop auth and auth-int are supported,
+// body of io.Reader is read into memory to be sent again
+func (r *Request) WithDigestAuth(username, password string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.auth = newDigestAuth(username, password)
+	})
+}
+
+// WithOAuth2 authenticate request with bearer token of OAuth2TokenSource,
+// request is sent again once with refreshed token when server respond 401,
+// body of io.Reader is read into memory to be sent again
+func (r *Request) WithOAuth2(source *OAuth2TokenSource) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.auth = &oauth2Auth{source: source}
+	})
+}
+
+// WithAWSSigV4 sign request with AWS signature version 4 when it's sent, after all headers and query are applied,
+// X-Amz-Date header is used as signing time if it's set, body of io.Reader is not signed, see AWSUnsignedPayload
+func (r *Request) WithAWSSigV4(credentials AWSCredentials, region, service string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.auth = &awsSigV4{credentials: credentials, region: region, service: service}
+	})
+}
+
+// WithSigner sign request with signer after url, query, headers and body are built, before request is sent,
+// body of io.Reader is read into memory to be signed
+func (r *Request) WithSigner(signer Signer) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.signer = signer
+	})
+}
+
+// WithBaseURL set base url, request url is resolved against it with RFC 3986 semantics
+//
+// base url should end with "/" to keep its last path segment:
+//
+//	"https://api/v1/" + "users" => "https://api/v1/users"
+//	"https://api/v1"  + "users" => "https://api/users"
+//
+// query of base url is merged into request url, resolved url with other scheme or host is rejected,
+// see WithAllowBaseURLEscape.
+func (r *Request) WithBaseURL(baseURL string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.baseURL = baseURL
+	})
+}
+
+// WithAllowBaseURLEscape set allow or not-allow request url resolved to other scheme or host than base url,
+// query of base url is not sent to other scheme or host
+func (r *Request) WithAllowBaseURLEscape(allow bool) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.isAllowBaseURLEscape = allow
+	})
+}
+
 // WithRedirect set allow or not-allow redirect with Location header
 func (r *Request) WithRedirect(b bool) *Request {
 	return r.configParamFactor(func(r *Request) {
@@ -151,12 +242,24 @@ func (r *Request) WithQuerys(kv map[string]string) *Request {
 	})
 }
 
-// WithQueryStruct set multi query k-v map
+// WithQueryStruct set multi query k-v map from struct fields with query tag
+//
+//	query:"name"                      a=1
+//	query:"name,omitempty"            skip zero value, nil pointer, empty slice and map
+//	query:"name,comma"                a=1,2
+//	query:"name,brackets"             a[]=1&a[]=2
+//	query:"name,repeat"               a=1&a=2 (default)
+//	query:"name,unix"                 time.Time as unix seconds, also: unixmilli, unixnano
+//	query:"name" layout:"2006-01-02"  time.Time with layout, default is time.RFC3339
+//
+// nil pointers are skipped, encoding.TextMarshaler and fmt.Stringer are used when implemented,
+// nested struct and map fields are encoded as name[key]=value,
+// embedded struct without query tag is flattened into the parent.
 func (r *Request) WithQueryStruct(v interface{}) *Request {
 	return r.configParamFactor(func(r *Request) {
 		kv, err := queryToMap(v)
 		if err != nil {
-			r.err = err
+			r.err = r.newError(KindInvalidRequest, "encode query struct", err)
 			return
 		}
 		for k, v := range kv {
@@ -165,18 +268,63 @@ func (r *Request) WithQueryStruct(v interface{}) *Request {
 	})
 }
 
+// WithRequestStruct set query, header, path params and body from struct fields with tags
+//
+//	type GetUserReq struct {
+//		ID     int64    `path:"id"`
+//		Fields []string `query:"fields,comma"`
+//		Token  string   `header:"Authorization,omitempty"`
+//		Body   *User    `body:"json"`
+//	}
+//
+// query, header and path tags support the same options as WithQueryStruct,
+// body tag is json (encoded as WithJSON), form (struct with query tags encoded as url encoded form) or raw (see WithBody),
+// it's used by Bind to encode request argument.
+func (r *Request) WithRequestStruct(v interface{}) *Request {
+	s, err := encodeRequestStruct(v)
+	if err != nil {
+		return r.configParamFactor(func(r *Request) {
+			r.err = r.newError(KindInvalidRequest, "encode request struct", err)
+		})
+	}
+	s.apply(r)
+	return r
+}
+
+// WithPathParam set one path param, replace {k} in url path with escaped v, "." and ".." are rejected
+//
+//	gorequests.New(http.MethodGet, "https://api/users/{id}").WithPathParam("id", "1")
+func (r *Request) WithPathParam(k, v string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.pathParams[k] = v
+	})
+}
+
+// WithPathParams set multi path param k-v map
+func (r *Request) WithPathParams(kv map[string]string) *Request {
+	return r.configParamFactor(func(r *Request) {
+		for k, v := range kv {
+			r.pathParams[k] = v
+		}
+	})
+}
+
 // WithBody set request body, support: io.Reader, []byte, string, interface{}(as json format)
 func (r *Request) WithBody(body interface{}) *Request {
 	return r.configParamFactor(func(r *Request) {
-		r.rawBody, r.body, r.err = toBody(body)
+		var err error
+		if r.rawBody, r.body, err = toBody(body); err != nil {
+			r.err = r.newError(KindInvalidRequest, "encode body", err)
+		}
 	})
 }
 
 // WithJSON set body same as WithBody, and set Content-Type to application/json
 func (r *Request) WithJSON(body interface{}) *Request {
 	return r.configParamFactor(func(r *Request) {
-		r.rawBody, r.body, r.err = toBody(body)
-		if r.err != nil {
+		var err error
+		if r.rawBody, r.body, err = toBody(body); err != nil {
+			r.err = r.newError(KindInvalidRequest, "encode body", err)
 			return
 		}
 		r.header.Set("Content-Type", "application/json")
@@ -190,7 +338,7 @@ func (r *Request) WithForm(body map[string]string) *Request {
 		f := multipart.NewWriter(&buf)
 		for k, v := range body {
 			if err := f.WriteField(k, v); err != nil {
-				r.err = err
+				r.err = r.newError(KindInvalidRequest, "encode form", err)
 				return
 			}
 		}
@@ -218,7 +366,7 @@ func (r *Request) WithFile(filename string, file io.Reader, fileKey string, para
 	return r.configParamFactor(func(r *Request) {
 		contentType, bod, err := newFileUploadRequest(params, fileKey, filename, file)
 		if err != nil {
-			r.err = err
+			r.err = r.newError(KindInvalidRequest, "encode file", err)
 			return
 		}
 		r.rawBody, r.body = nil, bod
@@ -235,7 +383,7 @@ func (r *Request) WithURLCookie(uri string) *Request {
 
 		uriParse, err := url.Parse(uri)
 		if err != nil {
-			r.err = err
+			r.err = r.newError(KindInvalidRequest, "parse cookie url", err)
 			return
 		}
 
@@ -249,6 +397,59 @@ func (r *Request) WithURLCookie(uri string) *Request {
 	})
 }
 
+// WithExpectStatus make Bytes, Text, Map and Unmarshal return *HTTPError when response status is not in codes,
+// any 2xx status is expected if codes is empty
+func (r *Request) WithExpectStatus(codes ...int) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.isCheckStatus = true
+		r.expectStatus = append([]int(nil), codes...)
+	})
+}
+
+// WithErrorResult make Unmarshal decode response body into val when status is not expected,
+// and return *HTTPError with Result set to val, see UnmarshalResult
+func (r *Request) WithErrorResult(val interface{}) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.errorResult = val
+	})
+}
+
+// WithEnvelope make Unmarshal decode data of envelope, and return *EnvelopeError when code is not success
+func (r *Request) WithEnvelope(envelope *Envelope) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.envelope = envelope
+	})
+}
+
+// WithJSONSchema validate json response body with schema before Unmarshal return
+func (r *Request) WithJSONSchema(schema *JSONSchema, mode SchemaMode) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.jsonSchema = schema
+		r.jsonSchemaMode = mode
+	})
+}
+
+// WithCache set http cache of request, see NewCache
+func (r *Request) WithCache(cache *Cache) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.cache = cache
+	})
+}
+
+// WithCoalescer share upstream call with identical in-flight requests of same coalescer, see NewCoalescer
+func (r *Request) WithCoalescer(coalescer *Coalescer) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.coalescer = coalescer
+	})
+}
+
+// WithHARRecorder record round trips of request as HAR entries, see NewHARRecorder
+func (r *Request) WithHARRecorder(recorder *HARRecorder) *Request {
+	return r.configParamFactor(func(r *Request) {
+		r.harRecorder = recorder
+	})
+}
+
 func (r *Request) WithLogProducer(producer LogProducer) *Request {
 	return r.configParamFactor(func(r *Request) {
 		r.logProducer = producer
@@ -274,7 +475,7 @@ func (r *Request) configParamFactor(f func(*Request)) *Request {
 	defer r.lock.Unlock()
 
 	if r.isRequest {
-		r.SetError(fmt.Errorf("request %s %s alreday sended, cannot set request params", r.method, r.cachedurl))
+		r.SetError(r.newError(KindAlreadySent, "set request params", ErrAlreadySent))
 		return r
 	}
 
@@ -19,17 +19,20 @@ type Request struct {
 	logger        Logger
 
 	// request
-	context      context.Context     // request context
-	isIgnoreSSL  bool                // request  ignore ssl verify
-	header       http.Header         // request header
-	querys       map[string][]string // request query
-	isNoRedirect bool                // request ignore redirect
-	timeout      time.Duration       // request timeout
-	url          string              // request url
-	method       string              // request method
-	rawBody      []byte              // []byte of body
-	body         io.Reader           // request body
-	fullUrl      string
+	context              context.Context     // request context
+	isIgnoreSSL          bool                // request  ignore ssl verify
+	header               http.Header         // request header
+	querys               map[string][]string // request query
+	pathParams           map[string]string   // request url path params
+	isNoRedirect         bool                // request ignore redirect
+	timeout              time.Duration       // request timeout
+	url                  string              // request url
+	baseURL              string              // request base url, url is resolved against it
+	isAllowBaseURLEscape bool                // request allow url resolved to other host than base url
+	method               string              // request method
+	rawBody              []byte              // []byte of body
+	body                 io.Reader           // request body
+	fullUrl              string
 
 	// resp
 	resp      *http.Response
@@ -37,6 +40,40 @@ type Request struct {
 	isRead    bool
 	isRequest bool
 
+	// json path
+	isJSONParsed bool
+	jsonValue    interface{}
+	jsonErr      error
+
+	// status check
+	isCheckStatus bool
+	expectStatus  []int
+	errorResult   interface{}
+	envelope      *Envelope
+
+	// cache
+	cache       *Cache
+	cacheStatus CacheStatus
+
+	// transport
+	transport http.RoundTripper
+
+	// auth
+	auth authenticator
+
+	// signer
+	signer Signer
+
+	// har
+	harRecorder *HARRecorder
+
+	// coalesce
+	coalescer *Coalescer
+
+	// json schema
+	jsonSchema     *JSONSchema
+	jsonSchemaMode SchemaMode
+
 	// log producer
 	logProducer LogProducer
 	isSend      bool
@@ -53,6 +90,7 @@ func New(method, url string) *Request {
 		method:      method,
 		header:      map[string][]string{},
 		querys:      make(map[string][]string),
+		pathParams:  make(map[string]string),
 		context:     context.TODO(),
 		logger:      NewStdoutLogger(),
 		logProducer: NewDiscardLogProducer(),
@@ -12,14 +12,14 @@ import (
 	"time"
 
 	"github.com/jloha/gorequests"
+	"github.com/jloha/gorequests/testserver"
 	"github.com/stretchr/testify/assert"
 )
 
+var httpBinServer = testserver.New()
+
 func joinHttpBinURL(path string) string {
-	if !strings.HasPrefix(path, "/") {
-		path = "/" + path
-	}
-	return "https://httpbin.org" + path
+	return httpBinServer.JoinURL(path)
 }
 
 func Test_Real(t *testing.T) {
@@ -77,7 +77,8 @@ func Test_Real(t *testing.T) {
 		text, err := gorequests.New(http.MethodGet, joinHttpBinURL("/delay/4")).WithTimeout(time.Second).Text()
 		as.Empty(text)
 		as.NotNil(err)
-		as.Contains(err.Error(), "context deadline exceeded")
+		as.True(errors.Is(err, gorequests.ErrTimeout))
+		as.Equal(gorequests.KindTimeout, gorequests.KindOf(err))
 	})
 
 	t.Run("/image", func(t *testing.T) {
@@ -204,7 +205,7 @@ func Test_LogProducer(t *testing.T) {
 	as := assert.New(t)
 
 	t.Run("/log_printer", func(t *testing.T) {
-		r := gorequests.New(http.MethodGet, "https://httpbin.org/get").WithLogProducer(gorequests.NewPrinterLogProducer()).WithTimeout(time.Second * 10)
+		r := gorequests.New(http.MethodGet, joinHttpBinURL("/get")).WithLogProducer(gorequests.NewPrinterLogProducer()).WithTimeout(time.Second * 10)
 		text, err := r.Text()
 		log := r.LogMessage()
 		as.Nil(err)
@@ -213,7 +214,7 @@ func Test_LogProducer(t *testing.T) {
 	})
 
 	t.Run("/url", func(t *testing.T) {
-		url := "https://httpbin.org/get?a=1"
+		url := joinHttpBinURL("/get?a=1")
 		r := gorequests.New(http.MethodGet, url).WithLogProducer(gorequests.NewPrinterLogProducer()).WithTimeout(time.Second * 10)
 		_, err := r.Text()
 		log := r.LogMessage()
@@ -223,7 +224,7 @@ func Test_LogProducer(t *testing.T) {
 
 	t.Run("/body", func(t *testing.T) {
 		body := `{"A":["1","2"], "B":"999"}`
-		r := gorequests.New(http.MethodGet, "https://httpbin.org/get").WithLogProducer(gorequests.NewPrinterLogProducer()).WithBody(body).WithTimeout(time.Second * 10)
+		r := gorequests.New(http.MethodGet, joinHttpBinURL("/get")).WithLogProducer(gorequests.NewPrinterLogProducer()).WithBody(body).WithTimeout(time.Second * 10)
 		_, err := r.Text()
 		log := r.LogMessage()
 		as.Nil(err)
@@ -231,7 +232,7 @@ func Test_LogProducer(t *testing.T) {
 	})
 
 	t.Run("/error", func(t *testing.T) {
-		r := gorequests.New(http.MethodGet, "https://httpbin.org/get").WithLogProducer(gorequests.NewPrinterLogProducer()).WithTimeout(time.Second * 10).SetDoError(errors.New("test error"))
+		r := gorequests.New(http.MethodGet, joinHttpBinURL("/get")).WithLogProducer(gorequests.NewPrinterLogProducer()).WithTimeout(time.Second * 10).SetDoError(errors.New("test error"))
 		_, err := r.Text()
 		log := r.LogMessage()
 		as.Nil(err)
@@ -240,7 +241,7 @@ func Test_LogProducer(t *testing.T) {
 	})
 
 	t.Run("/header", func(t *testing.T) {
-		r := gorequests.New(http.MethodGet, "https://httpbin.org/get").WithLogProducer(gorequests.NewPrinterLogProducer()).WithHeader("a", "1").WithHeader("a", "2").WithTimeout(time.Second * 10)
+		r := gorequests.New(http.MethodGet, joinHttpBinURL("/get")).WithLogProducer(gorequests.NewPrinterLogProducer()).WithHeader("a", "1").WithHeader("a", "2").WithTimeout(time.Second * 10)
 		_, err := r.Text()
 		log := r.LogMessage()
 		as.Nil(err)
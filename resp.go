@@ -4,19 +4,63 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
-	"reflect"
 )
 
+// Unmarshal send request and decode json response body into val,
+// if error result is set by WithErrorResult, see UnmarshalResult,
+// if envelope is set by WithEnvelope, data of envelope is decoded.
 func (r *Request) Unmarshal(val interface{}) error {
+	if r.errorResult != nil {
+		return r.UnmarshalResult(val, r.errorResult)
+	}
+
 	bs, err := r.Bytes()
 	if err != nil {
 		return err
 	}
+	return r.unmarshalResult(bs, val)
+}
+
+// UnmarshalResult send request, decode json response body into val when status is expected,
+// or into errVal with *HTTPError returned when status is not expected, any 2xx status is expected by default,
+// see WithExpectStatus, body of expected response is discarded if val is nil
+func (r *Request) UnmarshalResult(val, errVal interface{}) error {
+	bs, err := r.readBytes()
+	if err != nil {
+		return err
+	}
+	if !r.isExpectedStatus(r.resp.StatusCode) {
+		httpErr := newHTTPError(r, bs)
+		if errVal != nil && json.Unmarshal(bs, errVal) == nil {
+			httpErr.Result = errVal
+		}
+		return httpErr
+	}
+	return r.unmarshalResult(bs, val)
+}
+
+// unmarshalResult validate json schema, unwrap envelope and unmarshal
+func (r *Request) unmarshalResult(bs []byte, val interface{}) error {
+	if err := r.validateJSONSchema(bs); err != nil {
+		return err
+	}
+	if r.envelope != nil {
+		var err error
+		if bs, err = r.envelope.unwrap(r, bs); err != nil {
+			return err
+		}
+	}
+	return r.unmarshal(bs, val)
+}
+
+func (r *Request) unmarshal(bs []byte, val interface{}) error {
+	if val == nil {
+		return nil
+	}
 	if err := json.Unmarshal(bs, val); err != nil {
-		return fmt.Errorf("[gorequest] %s %s unmarshal %s to %s failed: %w", r.method, r.cachedurl, bs, reflect.TypeOf(val).Name(), err)
+		return r.newError(KindDecode, "unmarshal", decodeError(err, bs))
 	}
 	return nil
 }
@@ -33,7 +77,7 @@ func (r *Request) Map() (map[string]interface{}, error) {
 
 	m := make(map[string]interface{})
 	if err := json.Unmarshal(bs, &m); err != nil {
-		return nil, fmt.Errorf("[gorequest] %s %s unmarshal %s to map failed: %w", r.method, r.cachedurl, bs, err)
+		return nil, r.newError(KindDecode, "unmarshal", decodeError(err, bs))
 	}
 	return m, nil
 }
@@ -57,7 +101,20 @@ func (r *Request) MustText() string {
 	return val
 }
 
+// Bytes send request and read response body, return *HTTPError if status is not expected, see WithExpectStatus
 func (r *Request) Bytes() ([]byte, error) {
+	bs, err := r.readBytes()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkStatus(bs); err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
+
+// readBytes send request, read and decode response body, status is not checked
+func (r *Request) readBytes() ([]byte, error) {
 	if err := r.doRequest(); err != nil {
 		return nil, err
 	}
@@ -68,16 +125,38 @@ func (r *Request) Bytes() ([]byte, error) {
 	case "gzip":
 		bsReader, err := gzip.NewReader(bytes.NewReader(r.bytes))
 		if err != nil {
-			return nil, err
+			return nil, r.newError(KindDecode, "gzip decode", err)
 		}
 		defer bsReader.Close()
 		bs, err := ioutil.ReadAll(bsReader)
-		return bs, err
+		if err != nil {
+			return nil, r.newError(KindDecode, "gzip decode", err)
+		}
+		return bs, nil
 	}
 
 	return r.bytes, nil
 }
 
+func (r *Request) checkStatus(body []byte) error {
+	if !r.isCheckStatus || r.isExpectedStatus(r.resp.StatusCode) {
+		return nil
+	}
+	return newHTTPError(r, body)
+}
+
+func (r *Request) isExpectedStatus(status int) bool {
+	if len(r.expectStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, v := range r.expectStatus {
+		if v == status {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Request) MustBytes() []byte {
 	val, _ := r.Bytes()
 	return val
@@ -71,6 +71,7 @@ func newSession(cookiefile string, options []RequestOption) *Session {
 		Persistent: true,
 	})
 	if err != nil {
+		err = &Error{Kind: KindInvalidRequest, Op: "load cookie file " + cookiefile, Err: err}
 		return &Session{err: err, cookiefile: cookiefile, options: options}
 	} else {
 		return &Session{jar: jar, cookiefile: cookiefile, options: options}
@@ -0,0 +1,191 @@
+package gorequests
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidSignature is returned by verifiers when signature of request is missing or mismatched
+var ErrInvalidSignature = errors.New("gorequests: invalid signature")
+
+// Signer sign request after it's fully built, it's called once before request is sent,
+// and can add headers or query to req, see WithSigner
+type Signer interface {
+	Sign(req *SignRequest) error
+}
+
+// SignerFunc is a func implements Signer
+type SignerFunc func(req *SignRequest) error
+
+func (f SignerFunc) Sign(req *SignRequest) error {
+	return f(req)
+}
+
+// SignRequest is the built request passed to Signer
+type SignRequest struct {
+	Method string
+	URL    *url.URL    // final url with query, changes are sent
+	Header http.Header // request header, changes are sent
+	Body   []byte      // request body, body of io.Reader is read into memory before signing
+}
+
+// sign build SignRequest of r, and apply changes of signer to r
+func (r *Request) sign() error {
+	if r.rawBody == nil && r.body != nil {
+		bs, err := ioutil.ReadAll(r.body)
+		if err != nil {
+			return r.newError(KindInvalidRequest, "read body", err)
+		}
+		r.rawBody, r.body = bs, bytes.NewReader(bs)
+	}
+	u, err := url.Parse(r.cachedurl)
+	if err != nil {
+		return r.newError(KindInvalidRequest, "parse url", err)
+	}
+	req := &SignRequest{Method: r.method, URL: u, Header: r.header.Clone(), Body: r.rawBody}
+	if err := r.signer.Sign(req); err != nil {
+		return r.newError(KindAuth, "sign request", err)
+	}
+	r.cachedurl, r.header = req.URL.String(), req.Header
+	return nil
+}
+
+// newSignRequest build SignRequest of received request for verifiers, body of req is read and replaced
+func newSignRequest(req *http.Request) (*SignRequest, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		_ = req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	u := *req.URL
+	if u.Host == "" {
+		u.Host = req.Host
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+		if req.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	return &SignRequest{Method: req.Method, URL: &u, Header: req.Header.Clone(), Body: body}, nil
+}
+
+// HMACSigner sign request with shared key, for api schemes like: sign(method, path, sorted query, timestamp, nonce, body),
+// timestamp, nonce and signature are set to headers
+//
+//	signer := &gorequests.HMACSigner{Key: []byte("secret"), SignatureHeader: "X-Api-Sign"}
+//	fac := gorequests.NewFactory(gorequests.WithSigner(signer))
+type HMACSigner struct {
+	Key             []byte
+	Hash            func() hash.Hash // default is sha256.New
+	SignatureHeader string           // default is X-Signature
+	TimestampHeader string           // default is X-Timestamp
+	NonceHeader     string           // default is X-Nonce
+	// StringToSign build the signed string, default is HMACStringToSign
+	StringToSign func(req *SignRequest, timestamp, nonce string) string
+	Encode       func(sum []byte) string // encode signature, default is hex.EncodeToString
+	Timestamp    func() string           // default is unix seconds of current time
+	Nonce        func() string           // default is 16 random bytes in hex
+}
+
+// HMACStringToSign is the default StringToSign of HMACSigner, lines of:
+//
+//	method
+//	escaped path
+//	query sorted by name
+//	timestamp
+//	nonce
+//	hex sha256 of body
+func HMACStringToSign(req *SignRequest, timestamp, nonce string) string {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return req.Method + "\n" + path + "\n" + req.URL.Query().Encode() + "\n" + timestamp + "\n" + nonce + "\n" + hexSHA256(req.Body)
+}
+
+func (s *HMACSigner) Sign(req *SignRequest) error {
+	timestamp, nonce := strconv.FormatInt(time.Now().Unix(), 10), ""
+	if s.Timestamp != nil {
+		timestamp = s.Timestamp()
+	}
+	if s.Nonce != nil {
+		nonce = s.Nonce()
+	} else {
+		bs := make([]byte, 16)
+		if _, err := rand.Read(bs); err != nil {
+			return err
+		}
+		nonce = hex.EncodeToString(bs)
+	}
+
+	timestampHeader, nonceHeader, signatureHeader := s.headers()
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(nonceHeader, nonce)
+	req.Header.Set(signatureHeader, s.signature(req, timestamp, nonce))
+	return nil
+}
+
+// Verify check signature of received request, body of req is read and replaced, so it can be read again,
+// timestamp and nonce are not checked, caller should reject expired or replayed requests
+func (s *HMACSigner) Verify(req *http.Request) error {
+	timestampHeader, nonceHeader, signatureHeader := s.headers()
+	signature := req.Header.Get(signatureHeader)
+	if signature == "" {
+		return fmt.Errorf("%w: header %s is empty", ErrInvalidSignature, signatureHeader)
+	}
+	signReq, err := newSignRequest(req)
+	if err != nil {
+		return err
+	}
+	expected := s.signature(signReq, req.Header.Get(timestampHeader), req.Header.Get(nonceHeader))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+	}
+	return nil
+}
+
+func (s *HMACSigner) headers() (timestamp, nonce, signature string) {
+	timestamp, nonce, signature = s.TimestampHeader, s.NonceHeader, s.SignatureHeader
+	if timestamp == "" {
+		timestamp = "X-Timestamp"
+	}
+	if nonce == "" {
+		nonce = "X-Nonce"
+	}
+	if signature == "" {
+		signature = "X-Signature"
+	}
+	return timestamp, nonce, signature
+}
+
+func (s *HMACSigner) signature(req *SignRequest, timestamp, nonce string) string {
+	newHash, stringToSign, encode := s.Hash, s.StringToSign, s.Encode
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	if stringToSign == nil {
+		stringToSign = HMACStringToSign
+	}
+	if encode == nil {
+		encode = hex.EncodeToString
+	}
+	h := hmac.New(newHash, s.Key)
+	_, _ = h.Write([]byte(stringToSign(req, timestamp, nonce)))
+	return encode(h.Sum(nil))
+}
@@ -0,0 +1,303 @@
+package gorequests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWSUnsignedPayload is the payload hash of AWS signature version 4 when body is not signed,
+// it's used for io.Reader body which can not be read twice
+const AWSUnsignedPayload = "UNSIGNED-PAYLOAD"
+
+const (
+	awsSigV4Algorithm  = "AWS4-HMAC-SHA256"
+	awsSigV4TimeFormat = "20060102T150405Z"
+	awsSigV4DateFormat = "20060102"
+	awsSigV4MaxExpires = 7 * 24 * time.Hour
+)
+
+// awsSigV4IgnoredHeaders are not signed, they may be changed by proxies or added after signing
+var awsSigV4IgnoredHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+	"content-length":  true,
+}
+
+// AWSCredentials is the credentials of AWS signature version 4
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // session token of temporary credentials, sent as X-Amz-Security-Token
+}
+
+// awsSigV4 sign request with AWS signature version 4
+type awsSigV4 struct {
+	credentials AWSCredentials
+	region      string
+	service     string
+}
+
+func (a *awsSigV4) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		var err error
+		if payloadHash, err = awsPayloadHash(req); err != nil {
+			closeRequestBody(req)
+			return nil, &authError{err: err}
+		}
+	}
+	signTime, err := awsSigningTime(req.Header.Get("X-Amz-Date"))
+	if err != nil {
+		closeRequestBody(req)
+		return nil, &authError{err: err}
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Amz-Date", signTime.Format(awsSigV4TimeFormat))
+	if a.credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.credentials.SessionToken)
+	}
+	if a.service == "s3" || payloadHash == AWSUnsignedPayload {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	canonicalHeaders, signedHeaders := awsCanonicalHeaders(req.Header, host)
+	signature := a.signature(signTime, strings.Join([]string{
+		req.Method,
+		a.canonicalURI(req.URL),
+		awsCanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n"))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsSigV4Algorithm, a.credentials.AccessKeyID, a.scope(signTime), signedHeaders, signature))
+	return next.RoundTrip(req)
+}
+
+// presign return url with signature in query, which is valid for expires
+func (a *awsSigV4) presign(method string, u *url.URL, header http.Header, payloadHash string, signTime time.Time, expires time.Duration) string {
+	canonicalHeaders, signedHeaders := awsCanonicalHeaders(header, u.Host)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", awsSigV4Algorithm)
+	query.Set("X-Amz-Credential", a.credentials.AccessKeyID+"/"+a.scope(signTime))
+	query.Set("X-Amz-Date", signTime.Format(awsSigV4TimeFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+	if a.credentials.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", a.credentials.SessionToken)
+	}
+	canonicalQuery := awsCanonicalQuery(query)
+	signature := a.signature(signTime, strings.Join([]string{
+		method,
+		a.canonicalURI(u),
+		canonicalQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n"))
+
+	res := *u
+	res.RawQuery = canonicalQuery + "&X-Amz-Signature=" + signature
+	return res.String()
+}
+
+// PresignAWSSigV4 return url of request signed with AWS signature version 4 in query, which is valid for expires,
+// expires should be between 1 second and 7 days,
+// it does not send request, headers of request are signed and should be sent with the url,
+// X-Amz-Date header is used as signing time if it's set, payload is unsigned for s3 and io.Reader body,
+// and is hash of body for other services unless X-Amz-Content-Sha256 header is set
+func (r *Request) PresignAWSSigV4(credentials AWSCredentials, region, service string, expires time.Duration) (string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.err != nil {
+		return "", r.err
+	}
+	if expires < time.Second || expires > awsSigV4MaxExpires {
+		return "", r.newError(KindInvalidRequest, "presign", fmt.Errorf("expires %s is out of range [1s, 7d]", expires))
+	}
+	rawURL, err := r.buildRequestURL()
+	if err != nil {
+		return "", r.newError(KindInvalidRequest, "build url", err)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", r.newError(KindInvalidRequest, "parse url", err)
+	}
+	signTime, err := awsSigningTime(r.header.Get("X-Amz-Date"))
+	if err != nil {
+		return "", r.newError(KindInvalidRequest, "presign", err)
+	}
+	header := r.header.Clone()
+	header.Del("X-Amz-Date")
+	payloadHash := header.Get("X-Amz-Content-Sha256")
+	header.Del("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		if service == "s3" || (r.rawBody == nil && r.body != nil) {
+			payloadHash = AWSUnsignedPayload
+		} else {
+			payloadHash = hexSHA256(r.rawBody)
+		}
+	}
+
+	a := &awsSigV4{credentials: credentials, region: region, service: service}
+	return a.presign(r.method, u, header, payloadHash, signTime, expires), nil
+}
+
+func (a *awsSigV4) scope(signTime time.Time) string {
+	return strings.Join([]string{signTime.Format(awsSigV4DateFormat), a.region, a.service, "aws4_request"}, "/")
+}
+
+// signature return hex signature of canonical request
+func (a *awsSigV4) signature(signTime time.Time, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		awsSigV4Algorithm,
+		signTime.Format(awsSigV4TimeFormat),
+		a.scope(signTime),
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+a.credentials.SecretAccessKey), signTime.Format(awsSigV4DateFormat))
+	for _, v := range []string{a.region, a.service, "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalURI encode path once for s3, and twice for other services
+func (a *awsSigV4) canonicalURI(u *url.URL) string {
+	var path string
+	if a.service == "s3" {
+		path = awsURIEncode(u.Path, false)
+	} else {
+		path = awsURIEncode(u.EscapedPath(), false)
+	}
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// awsSigningTime parse X-Amz-Date header as signing time, or return current time if it's empty
+func awsSigningTime(amzDate string) (time.Time, error) {
+	if amzDate == "" {
+		return time.Now().UTC(), nil
+	}
+	t, err := time.Parse(awsSigV4TimeFormat, amzDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid X-Amz-Date %q: %w", amzDate, err)
+	}
+	return t, nil
+}
+
+// awsPayloadHash return hex sha256 of body, body without GetBody is stream and not signed
+func awsPayloadHash(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return hexSHA256(nil), nil
+	}
+	if req.GetBody == nil {
+		return AWSUnsignedPayload, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", fmt.Errorf("read request body: %w", err)
+	}
+	defer body.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", fmt.Errorf("read request body: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// awsCanonicalHeaders return canonical headers and signed headers, host and all headers except awsSigV4IgnoredHeaders are signed
+func awsCanonicalHeaders(header http.Header, host string) (string, string) {
+	values := map[string]string{"host": host}
+	for k, v := range header {
+		k = strings.ToLower(k)
+		if awsSigV4IgnoredHeaders[k] || k == "host" {
+			continue
+		}
+		trimmed := make([]string, len(v))
+		for i, s := range v {
+			trimmed[i] = strings.Join(strings.Fields(s), " ")
+		}
+		if old, ok := values[k]; ok {
+			values[k] = old + "," + strings.Join(trimmed, ",")
+		} else {
+			values[k] = strings.Join(trimmed, ",")
+		}
+	}
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	buf := new(strings.Builder)
+	for _, k := range names {
+		buf.WriteString(k + ":" + values[k] + "\n")
+	}
+	return buf.String(), strings.Join(names, ";")
+}
+
+// awsCanonicalQuery encode query sorted by name and value
+func awsCanonicalQuery(query url.Values) string {
+	encoded := make(map[string][]string, len(query))
+	for k, vs := range query {
+		key := awsURIEncode(k, true)
+		for _, v := range vs {
+			encoded[key] = append(encoded[key], awsURIEncode(v, true))
+		}
+	}
+	pairs := make([]string, 0, len(query))
+	for _, k := range sortedKeys(encoded) {
+		sort.Strings(encoded[k])
+		for _, v := range encoded[k] {
+			pairs = append(pairs, k+"="+v)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent encode all bytes except unreserved characters, "/" is kept if encodeSlash is false
+func awsURIEncode(s string, encodeSlash bool) string {
+	buf := new(strings.Builder)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
+
+func hexSHA256(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
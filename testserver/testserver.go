@@ -0,0 +1,289 @@
+// Package testserver start an in-process httpbin compatible server for tests.
+//
+// supported endpoints:
+//
+//	/get, /post, /put, /patch, /delete  echo request, method must match
+//	/anything, /anything/*             echo request of any method
+//	/headers                           request headers
+//	/ip                                client ip
+//	/status/{code}                     response with status code
+//	/delay/{n}                         delay n seconds (at most 10) and echo request
+//	/cookies                           request cookies
+//	/cookies/set?name=value            set cookies and redirect to /cookies
+//	/redirect/{n}                      redirect n times, then redirect to /get
+//	/gzip                              gzip encoded response
+//
+// usage:
+//
+//	server := testserver.New()
+//	defer server.Close()
+//	gorequests.New(http.MethodGet, server.URL+"/get")
+package testserver
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDelay is the max delay of /delay/{n}
+const maxDelay = 10
+
+// Server is a httpbin compatible test server
+type Server struct {
+	*httptest.Server
+}
+
+// New start server, it should be closed by caller
+func New() *Server {
+	return &Server{Server: httptest.NewServer(Handler())}
+}
+
+// NewTLS start https server, use server.Client() or WithIgnoreSSL to send request to it
+func NewTLS() *Server {
+	return &Server{Server: httptest.NewTLSServer(Handler())}
+}
+
+// JoinURL join server url and path
+func (s *Server) JoinURL(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return s.URL + path
+}
+
+// Handler return http handler of httpbin endpoints, it can be mounted to custom server
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		mux.HandleFunc("/"+strings.ToLower(method), methodHandler(method, echo))
+	}
+	mux.HandleFunc("/anything", echo)
+	mux.HandleFunc("/anything/", echo)
+	mux.HandleFunc("/headers", headers)
+	mux.HandleFunc("/ip", ip)
+	mux.HandleFunc("/status/", status)
+	mux.HandleFunc("/delay/", delay)
+	mux.HandleFunc("/cookies", cookies)
+	mux.HandleFunc("/cookies/set", setCookies)
+	mux.HandleFunc("/redirect/", redirect)
+	mux.HandleFunc("/gzip", gzipHandler)
+	return mux
+}
+
+func methodHandler(method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func echo(w http.ResponseWriter, r *http.Request) {
+	res := map[string]interface{}{
+		"args":    flatten(r.URL.Query()),
+		"headers": headerMap(r),
+		"origin":  origin(r),
+		"url":     fullURL(r),
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	default:
+		data, form, files, jsonBody, err := parseBody(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		res["data"], res["form"], res["files"], res["json"] = data, form, files, jsonBody
+	}
+	if strings.HasPrefix(r.URL.Path, "/anything") {
+		res["method"] = r.Method
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func headers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"headers": headerMap(r)})
+}
+
+func ip(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"origin": origin(r)})
+}
+
+func status(w http.ResponseWriter, r *http.Request) {
+	code, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/status/"))
+	if err != nil || code < 100 || code > 999 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid status code"})
+		return
+	}
+	if code >= 300 && code < 400 && code != http.StatusNotModified {
+		w.Header().Set("Location", "/redirect/1")
+	}
+	w.WriteHeader(code)
+}
+
+func delay(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.ParseFloat(strings.TrimPrefix(r.URL.Path, "/delay/"), 64)
+	if err != nil || n < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid delay"})
+		return
+	}
+	if n > maxDelay {
+		n = maxDelay
+	}
+	timer := time.NewTimer(time.Duration(n * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-r.Context().Done():
+		return
+	}
+	echo(w, r)
+}
+
+func cookies(w http.ResponseWriter, r *http.Request) {
+	res := map[string]string{}
+	for _, v := range r.Cookies() {
+		res[v.Name] = v.Value
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cookies": res})
+}
+
+func setCookies(w http.ResponseWriter, r *http.Request) {
+	for k, vs := range r.URL.Query() {
+		for _, v := range vs {
+			http.SetCookie(w, &http.Cookie{Name: k, Value: v, Path: "/"})
+		}
+	}
+	http.Redirect(w, r, "/cookies", http.StatusFound)
+}
+
+func redirect(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+	if err != nil || n < 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid redirect count"})
+		return
+	}
+	if n == 1 {
+		http.Redirect(w, r, "/get", http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, "/redirect/"+strconv.Itoa(n-1), http.StatusFound)
+}
+
+func gzipHandler(w http.ResponseWriter, r *http.Request) {
+	bs, _ := json.Marshal(map[string]interface{}{
+		"gzipped": true,
+		"headers": headerMap(r),
+		"method":  r.Method,
+		"origin":  origin(r),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Encoding", "gzip")
+	gw := gzip.NewWriter(w)
+	_, _ = gw.Write(bs)
+	_ = gw.Close()
+}
+
+func parseBody(r *http.Request) (data string, form map[string]interface{}, files map[string]interface{}, jsonBody interface{}, err error) {
+	form, files = map[string]interface{}{}, map[string]interface{}{}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		if err = r.ParseMultipartForm(32 << 20); err != nil {
+			return
+		}
+		form = flatten(r.MultipartForm.Value)
+		for name, headers := range r.MultipartForm.File {
+			values := make([]string, 0, len(headers))
+			for _, header := range headers {
+				f, err := header.Open()
+				if err != nil {
+					return "", nil, nil, nil, err
+				}
+				bs, err := ioutil.ReadAll(f)
+				_ = f.Close()
+				if err != nil {
+					return "", nil, nil, nil, err
+				}
+				values = append(values, string(bs))
+			}
+			files[name] = flattenOne(values)
+		}
+	case "application/x-www-form-urlencoded":
+		if err = r.ParseForm(); err != nil {
+			return
+		}
+		form = flatten(r.PostForm)
+	default:
+		var bs []byte
+		if bs, err = ioutil.ReadAll(r.Body); err != nil {
+			return
+		}
+		data = string(bs)
+		if json.Valid(bs) {
+			_ = json.Unmarshal(bs, &jsonBody)
+		}
+	}
+	return
+}
+
+// headerMap join multiple values with ",", like httpbin
+func headerMap(r *http.Request) map[string]string {
+	res := map[string]string{"Host": r.Host}
+	for k, v := range r.Header {
+		res[k] = strings.Join(v, ",")
+	}
+	return res
+}
+
+// flatten use string for single value, and array for multiple values, like httpbin
+func flatten(values map[string][]string) map[string]interface{} {
+	res := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		res[k] = flattenOne(v)
+	}
+	return res
+}
+
+func flattenOne(v []string) interface{} {
+	if len(v) == 1 {
+		return v[0]
+	}
+	return v
+}
+
+func origin(r *http.Request) string {
+	if v := r.Header.Get("X-Forwarded-For"); v != "" {
+		return v
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func fullURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	bs, _ := json.MarshalIndent(v, "", "  ")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(append(bs, '\n'))
+}
@@ -0,0 +1,72 @@
+package testserver_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/jloha/gorequests/testserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Server(t *testing.T) {
+	as := assert.New(t)
+	server := testserver.New()
+	defer server.Close()
+	fac := gorequests.NewFactory(gorequests.WithLogger(gorequests.NewDiscardLogger()))
+
+	t.Run("/anything", func(t *testing.T) {
+		resp := struct {
+			Method string            `json:"method"`
+			Args   map[string]string `json:"args"`
+			JSON   map[string]string `json:"json"`
+		}{}
+		as.Nil(fac.New(http.MethodPatch, server.JoinURL("/anything/x")).WithQuery("a", "1").WithJSON(map[string]string{"b": "2"}).Unmarshal(&resp))
+		as.Equal(http.MethodPatch, resp.Method)
+		as.Equal("1", resp.Args["a"])
+		as.Equal("2", resp.JSON["b"])
+	})
+
+	t.Run("/post form", func(t *testing.T) {
+		resp := struct {
+			Form map[string]interface{} `json:"form"`
+		}{}
+		as.Nil(fac.New(http.MethodPost, server.JoinURL("/post")).WithFormURLEncoded(map[string]string{"a": "1"}).Unmarshal(&resp))
+		as.Equal("1", resp.Form["a"])
+
+		status, err := fac.New(http.MethodGet, server.JoinURL("/post")).ResponseStatus()
+		as.Nil(err)
+		as.Equal(http.StatusMethodNotAllowed, status)
+	})
+
+	t.Run("/redirect", func(t *testing.T) {
+		r := fac.New(http.MethodGet, server.JoinURL("/redirect/3"))
+		as.Nil(r.Unmarshal(&map[string]interface{}{}))
+		as.Equal("/get", r.MustResponse().Request.URL.Path)
+
+		status, err := fac.New(http.MethodGet, server.JoinURL("/redirect/3")).WithRedirect(false).ResponseStatus()
+		as.Nil(err)
+		as.Equal(http.StatusFound, status)
+	})
+
+	t.Run("/cookies/set", func(t *testing.T) {
+		s := gorequests.NewSession(t.TempDir()+"/cookie.json", gorequests.WithLogger(gorequests.NewDiscardLogger()))
+		resp := struct {
+			Cookies map[string]string `json:"cookies"`
+		}{}
+		as.Nil(s.New(http.MethodGet, server.JoinURL("/cookies/set?a=b")).Unmarshal(&resp))
+		as.Equal("b", resp.Cookies["a"])
+
+		resp.Cookies = nil
+		as.Nil(s.New(http.MethodGet, server.JoinURL("/cookies")).Unmarshal(&resp))
+		as.Equal("b", resp.Cookies["a"])
+	})
+
+	t.Run("/gzip", func(t *testing.T) {
+		resp := struct {
+			Gzipped bool `json:"gzipped"`
+		}{}
+		as.Nil(fac.New(http.MethodGet, server.JoinURL("/gzip")).Unmarshal(&resp))
+		as.True(resp.Gzipped)
+	})
+}
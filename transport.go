@@ -0,0 +1,29 @@
+package gorequests
+
+import (
+	"net/http"
+	"sync"
+)
+
+var (
+	defaultTransportLock sync.RWMutex
+	defaultTransport     http.RoundTripper
+)
+
+// SetDefaultTransport set the base transport of all requests which have no transport set by WithTransport,
+// nil means http.DefaultTransport, it returns the previous one, so it can be restored
+func SetDefaultTransport(rt http.RoundTripper) http.RoundTripper {
+	defaultTransportLock.Lock()
+	defer defaultTransportLock.Unlock()
+
+	prev := defaultTransport
+	defaultTransport = rt
+	return prev
+}
+
+func getDefaultTransport() http.RoundTripper {
+	defaultTransportLock.RLock()
+	defer defaultTransportLock.RUnlock()
+
+	return defaultTransport
+}
@@ -0,0 +1,122 @@
+package gorequests_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEchoURLServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.RequestURI()))
+	}))
+}
+
+func Test_PathParam(t *testing.T) {
+	as := assert.New(t)
+	server := newEchoURLServer()
+	defer server.Close()
+
+	t.Run("escape", func(t *testing.T) {
+		r := gorequests.New(http.MethodGet, server.URL+"/users/{id}/orders/{orderID}?a=1").
+			WithPathParam("id", "a/b?c").
+			WithPathParams(map[string]string{"orderID": "1 2"}).
+			WithLogProducer(gorequests.NewPrinterLogProducer())
+		text, err := r.Text()
+		as.Nil(err)
+		as.Equal("/users/a%2Fb%3Fc/orders/1%202?a=1", text)
+		as.Equal(server.URL+"/users/{id}/orders/{orderID}?a=1", r.LogMessage().UrlTemplate)
+		as.Equal(server.URL+"/users/a%2Fb%3Fc/orders/1%202?a=1", r.LogMessage().Url)
+	})
+
+	t.Run("unresolved", func(t *testing.T) {
+		_, err := gorequests.New(http.MethodGet, server.URL+"/users/{id}/orders/{orderID}").
+			WithPathParam("id", "1").
+			Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "unresolved path param {orderID}")
+	})
+
+	t.Run("braces in query are not params", func(t *testing.T) {
+		text, err := gorequests.New(http.MethodGet, server.URL+"/get?q={x}").Text()
+		as.Nil(err)
+		as.Equal("/get?q=%7Bx%7D", text)
+	})
+
+	t.Run("dot segments", func(t *testing.T) {
+		for _, v := range []string{".", ".."} {
+			_, err := gorequests.New(http.MethodGet, server.URL+"/users/{id}/orders").WithPathParam("id", v).Text()
+			as.Equal(gorequests.KindInvalidRequest, gorequests.KindOf(err), v)
+			as.True(errors.Is(err, gorequests.ErrInvalidPathParam), v)
+		}
+
+		text, err := gorequests.New(http.MethodGet, server.URL+"/users/{id}").WithPathParam("id", "../admin").Text()
+		as.Nil(err)
+		as.Equal("/users/..%2Fadmin", text)
+	})
+
+	t.Run("only path is rendered", func(t *testing.T) {
+		// placeholders in host are not replaced, and fail to parse
+		_, err := gorequests.New(http.MethodGet, "http://{host}/users/{id}").
+			WithPathParams(map[string]string{"host": "evil.example.com", "id": "1"}).Text()
+		as.Equal(gorequests.KindInvalidRequest, gorequests.KindOf(err))
+
+		text, err := gorequests.New(http.MethodGet, server.URL+"/{host}").WithPathParam("host", "1").Text()
+		as.Nil(err)
+		as.Equal("/1", text)
+
+		fac := gorequests.NewFactory(gorequests.WithBaseURL(server.URL + "/v1/"))
+		text, err = fac.New(http.MethodGet, "{id}").WithPathParam("id", "1").Text()
+		as.Nil(err)
+		as.Equal("/v1/1", text)
+	})
+}
+
+func Test_BaseURL(t *testing.T) {
+	as := assert.New(t)
+	server := newEchoURLServer()
+	defer server.Close()
+
+	t.Run("factory", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithBaseURL(server.URL + "/v1/?token=t"))
+
+		text, err := fac.New(http.MethodGet, "users/{id}").WithPathParam("id", "1").WithQuery("a", "1").Text()
+		as.Nil(err)
+		as.Equal("/v1/users/1?a=1&token=t", text)
+
+		text, err = fac.New(http.MethodGet, "/root?token=override").Text()
+		as.Nil(err)
+		as.Equal("/root?token=override", text)
+
+		text, err = fac.New(http.MethodGet, "../up").Text()
+		as.Nil(err)
+		as.Equal("/up?token=t", text)
+	})
+
+	t.Run("session", func(t *testing.T) {
+		s := gorequests.NewSession(t.TempDir()+"/cookie.json", gorequests.WithBaseURL(server.URL))
+		text, err := s.New(http.MethodGet, "/get").Text()
+		as.Nil(err)
+		as.Equal("/get", text)
+	})
+
+	t.Run("escape", func(t *testing.T) {
+		fac := gorequests.NewFactory(gorequests.WithBaseURL(server.URL))
+		_, err := fac.New(http.MethodGet, "https://example.com/get").Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "escape base url")
+
+		_, err = fac.New(http.MethodGet, "//example.com/get").Text()
+		as.NotNil(err)
+		as.Contains(err.Error(), "escape base url")
+
+		fac = gorequests.NewFactory(gorequests.WithBaseURL("https://example.com?api_key=secret"), gorequests.WithAllowBaseURLEscape(true))
+		text, err := fac.New(http.MethodGet, server.URL+"/other?a=1").Text()
+		as.Nil(err)
+		as.Equal("/other?a=1", text)
+	})
+}
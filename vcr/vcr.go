@@ -0,0 +1,362 @@
+// Package vcr record http interactions to a cassette file, and replay them offline.
+//
+// install recorder on Factory or Session with gorequests.WithTransport:
+//
+//	rec, err := vcr.New("testdata/users.json", vcr.ModeRecordMissing,
+//		vcr.WithRedactHeaders("Authorization"),
+//		vcr.WithMatchOn(vcr.MatchMethod, vcr.MatchURL, vcr.MatchBody),
+//	)
+//	fac := gorequests.NewFactory(gorequests.WithTransport(rec))
+//
+// cassette is a json file, every recorded interaction is saved to it immediately.
+package vcr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrInteractionNotFound is returned in ModeReplayOnly when request match no recorded interaction
+var ErrInteractionNotFound = errors.New("vcr: interaction not found in cassette")
+
+// Mode is the mode of recorder
+type Mode int
+
+const (
+	ModeRecord        Mode = iota // always send request, and record all interactions to a new cassette
+	ModeReplayOnly                // only replay recorded interactions, never send request
+	ModeRecordMissing             // replay recorded interactions, send and record request which is not recorded
+)
+
+// Redacted replace value of redacted header
+const Redacted = "REDACTED"
+
+// request signature parts, see WithMatchOn
+const (
+	MatchMethod = "method"
+	MatchURL    = "url"   // full url, with query
+	MatchPath   = "path"  // scheme, host and path, without query
+	MatchQuery  = "query" // query, order of keys is ignored
+	MatchBody   = "body"
+)
+
+// MatchHeader return signature part of request header value
+func MatchHeader(name string) string {
+	return "header:" + http.CanonicalHeaderKey(name)
+}
+
+// Cassette is the content of cassette file
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request/response pair
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request
+type Request struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 bool        `json:"body_base64,omitempty"` // body is base64 encoded, because it's not utf-8
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 bool        `json:"body_base64,omitempty"`
+}
+
+// Option config recorder
+type Option func(r *Recorder)
+
+// WithMatchOn set request signature used to find recorded interaction, default is method and url
+func WithMatchOn(parts ...string) Option {
+	return func(r *Recorder) {
+		r.matchOn = parts
+	}
+}
+
+// WithRedactHeaders replace value of request and response headers with Redacted in cassette
+func WithRedactHeaders(names ...string) Option {
+	return func(r *Recorder) {
+		for _, v := range names {
+			r.redactHeaders = append(r.redactHeaders, http.CanonicalHeaderKey(v))
+		}
+	}
+}
+
+// WithRedactBody rewrite request and response body before it's saved to cassette, and before
+// request body is used in signature, see RedactJSONFields
+func WithRedactBody(f func(body []byte) []byte) Option {
+	return func(r *Recorder) {
+		r.redactBody = f
+	}
+}
+
+// WithTransport set transport used to send request in record modes, default is http.DefaultTransport
+func WithTransport(rt http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.next = rt
+	}
+}
+
+// RedactJSONFields return body redactor which replace value of top level json object fields with Redacted,
+// body which is not json object is kept
+func RedactJSONFields(fields ...string) func(body []byte) []byte {
+	return func(body []byte) []byte {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(body, &obj); err != nil {
+			return body
+		}
+		for _, field := range fields {
+			if _, ok := obj[field]; ok {
+				obj[field] = json.RawMessage(`"` + Redacted + `"`)
+			}
+		}
+		bs, err := json.Marshal(obj)
+		if err != nil {
+			return body
+		}
+		return bs
+	}
+}
+
+// Recorder is a http.RoundTripper which record and replay interactions of cassette
+type Recorder struct {
+	path          string
+	mode          Mode
+	matchOn       []string
+	redactHeaders []string
+	redactBody    func(body []byte) []byte
+	next          http.RoundTripper
+
+	lock     sync.Mutex
+	cassette *Cassette
+	replayed map[*Interaction]bool
+}
+
+// New create recorder of cassette file, in ModeRecord existing cassette is discarded,
+// in ModeReplayOnly cassette file must exist
+func New(path string, mode Mode, options ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:     path,
+		mode:     mode,
+		matchOn:  []string{MatchMethod, MatchURL},
+		next:     http.DefaultTransport,
+		cassette: &Cassette{},
+		replayed: map[*Interaction]bool{},
+	}
+	for _, v := range options {
+		v(r)
+	}
+
+	if mode == ModeRecord {
+		return r, nil
+	}
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && mode == ModeRecordMissing {
+			return r, nil
+		}
+		return nil, fmt.Errorf("vcr: load cassette %s: %w", path, err)
+	}
+	if err := json.Unmarshal(bs, r.cassette); err != nil {
+		return nil, fmt.Errorf("vcr: load cassette %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Cassette return recorded interactions
+func (r *Recorder) Cassette() *Cassette {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return &Cassette{Interactions: append([]*Interaction(nil), r.cassette.Interactions...)}
+}
+
+// RoundTrip implement http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	recorded := r.recordRequest(req, body)
+	signature := r.signature(&recorded)
+
+	if r.mode != ModeRecord {
+		r.lock.Lock()
+		interaction := r.find(signature)
+		r.lock.Unlock()
+		if interaction != nil {
+			return interaction.Response.build(req)
+		}
+		if r.mode == ModeReplayOnly {
+			return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, req.Method, req.URL)
+		}
+	}
+
+	// send clone with original body, recorded body may be redacted, and req should not be modified
+	send := req.Clone(req.Context())
+	if req.Body != nil {
+		send.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := r.next.RoundTrip(send)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &Interaction{Request: recorded, Response: r.recordResponse(resp, respBody)}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.replayed[interaction] = true
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// find return first not replayed interaction with signature, or the last replayed one
+func (r *Recorder) find(signature string) *Interaction {
+	var last *Interaction
+	for _, v := range r.cassette.Interactions {
+		if r.signature(&v.Request) != signature {
+			continue
+		}
+		if !r.replayed[v] {
+			r.replayed[v] = true
+			return v
+		}
+		last = v
+	}
+	return last
+}
+
+func (r *Recorder) save() error {
+	bs, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("vcr: save cassette %s: %w", r.path, err)
+		}
+	}
+	if err := ioutil.WriteFile(r.path, bs, 0o644); err != nil {
+		return fmt.Errorf("vcr: save cassette %s: %w", r.path, err)
+	}
+	return nil
+}
+
+func (r *Recorder) signature(req *Request) string {
+	parts := make([]string, 0, len(r.matchOn))
+	for _, v := range r.matchOn {
+		switch {
+		case v == MatchMethod:
+			parts = append(parts, req.Method)
+		case v == MatchURL:
+			parts = append(parts, req.URL)
+		case v == MatchPath:
+			parts = append(parts, strings.SplitN(req.URL, "?", 2)[0])
+		case v == MatchQuery:
+			query := ""
+			if idx := strings.IndexByte(req.URL, '?'); idx >= 0 {
+				query = req.URL[idx+1:]
+			}
+			pairs := strings.Split(query, "&")
+			sort.Strings(pairs)
+			parts = append(parts, strings.Join(pairs, "&"))
+		case v == MatchBody:
+			parts = append(parts, req.Body)
+		case strings.HasPrefix(v, "header:"):
+			parts = append(parts, strings.Join(req.Header.Values(strings.TrimPrefix(v, "header:")), ", "))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (r *Recorder) recordRequest(req *http.Request, body []byte) Request {
+	res := Request{Method: req.Method, URL: req.URL.String(), Header: r.redactHeader(req.Header)}
+	res.Body, res.BodyBase64 = r.encodeBody(body)
+	return res
+}
+
+func (r *Recorder) recordResponse(resp *http.Response, body []byte) Response {
+	res := Response{StatusCode: resp.StatusCode, Header: r.redactHeader(resp.Header)}
+	res.Body, res.BodyBase64 = r.encodeBody(body)
+	return res
+}
+
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	res := header.Clone()
+	for _, v := range r.redactHeaders {
+		if _, ok := res[v]; ok {
+			res[v] = []string{Redacted}
+		}
+	}
+	return res
+}
+
+func (r *Recorder) encodeBody(body []byte) (string, bool) {
+	if r.redactBody != nil && len(body) > 0 {
+		body = r.redactBody(body)
+	}
+	if utf8.Valid(body) {
+		return string(body), false
+	}
+	return base64.StdEncoding.EncodeToString(body), true
+}
+
+func (r *Response) build(req *http.Request) (*http.Response, error) {
+	body := []byte(r.Body)
+	if r.BodyBase64 {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(r.Body); err != nil {
+			return nil, fmt.Errorf("vcr: decode recorded body: %w", err)
+		}
+	}
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,116 @@
+package vcr_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jloha/gorequests"
+	"github.com/jloha/gorequests/vcr"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCountServer(hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(hits, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte(`{"hit":` + strconv.Itoa(int(n)) + `,"body":` + strconv.Quote(string(body)) + `,"token":"t1"}`))
+	}))
+}
+
+func Test_VCR(t *testing.T) {
+	as := assert.New(t)
+	var hits int32
+	server := newCountServer(&hits)
+	defer server.Close()
+	cassette := t.TempDir() + "/cassette.json"
+	options := []vcr.Option{
+		vcr.WithMatchOn(vcr.MatchMethod, vcr.MatchPath, vcr.MatchQuery, vcr.MatchBody),
+		vcr.WithRedactHeaders("Authorization", "Set-Cookie"),
+		vcr.WithRedactBody(vcr.RedactJSONFields("token", "password")),
+	}
+	send := func(rec *vcr.Recorder, method, url string, body interface{}) (string, error) {
+		r := gorequests.New(method, url).WithTransport(rec).WithLogger(gorequests.NewDiscardLogger()).
+			WithHeader("Authorization", "Bearer secret")
+		if body != nil {
+			r.WithJSON(body)
+		}
+		return r.Text()
+	}
+
+	t.Run("record", func(t *testing.T) {
+		rec, err := vcr.New(cassette, vcr.ModeRecord, options...)
+		as.Nil(err)
+		text, err := send(rec, http.MethodGet, server.URL+"/get?a=1&b=2", nil)
+		as.Nil(err)
+		as.Equal(`{"hit":1,"body":"","token":"t1"}`, text)
+		_, err = send(rec, http.MethodPost, server.URL+"/post", map[string]string{"name": "bob", "password": "p"})
+		as.Nil(err)
+		as.Equal(int32(2), atomic.LoadInt32(&hits))
+
+		bs, err := ioutil.ReadFile(cassette)
+		as.Nil(err)
+		as.NotContains(string(bs), "secret")
+		as.NotContains(string(bs), `"p"`)
+		as.NotContains(string(bs), "t1")
+		as.Contains(string(bs), vcr.Redacted)
+	})
+
+	t.Run("replay only", func(t *testing.T) {
+		rec, err := vcr.New(cassette, vcr.ModeReplayOnly, options...)
+		as.Nil(err)
+
+		text, err := send(rec, http.MethodGet, server.URL+"/get?b=2&a=1", nil)
+		as.Nil(err)
+		as.Equal(`{"body":"","hit":1,"token":"REDACTED"}`, text)
+		_, err = send(rec, http.MethodPost, server.URL+"/post", map[string]string{"name": "bob", "password": "other"})
+		as.Nil(err)
+		as.Equal(int32(2), atomic.LoadInt32(&hits))
+
+		_, err = send(rec, http.MethodPost, server.URL+"/post", map[string]string{"name": "alice"})
+		as.True(errors.Is(err, vcr.ErrInteractionNotFound), err)
+		as.Equal(int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("record missing", func(t *testing.T) {
+		rec, err := vcr.New(cassette, vcr.ModeRecordMissing, options...)
+		as.Nil(err)
+
+		_, err = send(rec, http.MethodGet, server.URL+"/get?a=1&b=2", nil)
+		as.Nil(err)
+		text, err := send(rec, http.MethodGet, server.URL+"/new", nil)
+		as.Nil(err)
+		as.Equal(`{"hit":3,"body":"","token":"t1"}`, text)
+		as.Equal(int32(3), atomic.LoadInt32(&hits))
+		as.Len(rec.Cassette().Interactions, 3)
+
+		rec, err = vcr.New(cassette, vcr.ModeReplayOnly, options...)
+		as.Nil(err)
+		_, err = send(rec, http.MethodGet, server.URL+"/new", nil)
+		as.Nil(err)
+		as.Equal(int32(3), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("replay only without cassette", func(t *testing.T) {
+		_, err := vcr.New(t.TempDir()+"/not-exist.json", vcr.ModeReplayOnly)
+		as.NotNil(err)
+	})
+
+	t.Run("request is not modified", func(t *testing.T) {
+		rec, err := vcr.New(t.TempDir()+"/cassette.json", vcr.ModeRecord)
+		as.Nil(err)
+		body := ioutil.NopCloser(strings.NewReader("body"))
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/post", body)
+		as.Nil(err)
+		resp, err := rec.RoundTrip(req)
+		as.Nil(err)
+		_ = resp.Body.Close()
+		as.Equal(body, req.Body)
+	})
+}